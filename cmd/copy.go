@@ -0,0 +1,110 @@
+// Copyright © 2017 Aidan Steele <aidan.steele@glassechidna.com.au>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/glassechidna/pstore/pkg/pstore"
+	"github.com/spf13/cobra"
+)
+
+var copyCmd = &cobra.Command{
+	Use:   "copy <source path> <target path>",
+	Short: "Copies a tree of parameters to another path, region or account",
+	Long: `
+Copies every parameter under the source path to the same relative name under
+the target path, preserving type, KMS key, tier, description, allowed pattern
+and tags. The creates and overwrites are listed before anything is written,
+and --dry-run stops there. Nothing is ever deleted from the target.
+
+Either side can be in another region or account with --source-region,
+--target-region, --source-role and --target-role. SecureStrings encrypted with
+a customer managed key will usually need it mapping to a key that exists on
+the target side with --key-map.
+
+example:
+	pstore copy /app/staging /app/prod --exclude 'DB_*' --target-region us-west-2`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(pstore.UsageError)
+		}
+
+		flags := cmd.Flags()
+		sourceRegion, _ := flags.GetString("source-region")
+		sourceRole, _ := flags.GetString("source-role")
+		targetRegion, _ := flags.GetString("target-region")
+		targetRole, _ := flags.GetString("target-role")
+		keyMappings, _ := flags.GetStringArray("key-map")
+
+		req := pstore.CopyRequest{
+			Source:     pstore.NewSessionFor(sourceRegion, sourceRole),
+			SourcePath: args[0],
+			Target:     pstore.NewSessionFor(targetRegion, targetRole),
+			TargetPath: args[1],
+			KeyMap:     map[string]string{},
+		}
+		req.Include, _ = flags.GetStringArray("include")
+		req.Exclude, _ = flags.GetStringArray("exclude")
+
+		for _, mapping := range keyMappings {
+			pair := strings.SplitN(mapping, "=", 2)
+			if len(pair) != 2 {
+				pstore.Abort(pstore.UsageError, fmt.Sprintf("key mapping '%s' should be in SOURCE=TARGET form", mapping))
+			}
+			req.KeyMap[pair[0]] = pair[1]
+		}
+
+		dryRun, _ := flags.GetBool("dry-run")
+		yes, _ := flags.GetBool("yes")
+		concurrency, _ := flags.GetInt("concurrency")
+		rate, _ := flags.GetInt("rate")
+
+		changes, err := pstore.PlanCopy(req)
+		if err != nil {
+			pstore.Abort(pstore.PstoreError, err)
+		}
+
+		if !printChanges(changes, pstoreOptions().Verbose) {
+			fmt.Fprintln(os.Stderr, "Nothing to do")
+			return
+		}
+
+		if dryRun {
+			return
+		}
+
+		confirm(yes, "y", "Apply these changes? [y/N] ")
+		applyChanges(req.Target, changes, concurrency, rate)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(copyCmd)
+	copyCmd.Flags().StringArray("include", nil, "Only copy relative names matching this glob, may be repeated")
+	copyCmd.Flags().StringArray("exclude", nil, "Don't copy relative names matching this glob, may be repeated")
+	copyCmd.Flags().StringArray("key-map", nil, "Encrypt with a different KMS key on the target, in SOURCE=TARGET form. May be repeated")
+	copyCmd.Flags().String("source-region", "", "Region of the source path (defaults to the usual region)")
+	copyCmd.Flags().String("source-role", "", "Role to assume to read the source path")
+	copyCmd.Flags().String("target-region", "", "Region of the target path (defaults to the usual region)")
+	copyCmd.Flags().String("target-role", "", "Role to assume to write the target path")
+	copyCmd.Flags().Bool("dry-run", false, "Only show what would change")
+	copyCmd.Flags().Bool("yes", false, "Don't ask for confirmation")
+	copyCmd.Flags().Int("concurrency", 2, "Maximum number of parameters written at once")
+	copyCmd.Flags().Int("rate", 3, "Maximum number of parameters written per second")
+}
@@ -0,0 +1,141 @@
+// Copyright © 2017 Aidan Steele <aidan.steele@glassechidna.com.au>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/glassechidna/pstore/pkg/pstore"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <source path> <target path>",
+	Short: "Compares the parameters under two paths",
+	Long: `
+Compares the keys under two paths, relative to each path, and shows which
+were added, removed or changed going from the source to the target. Values are
+shown as hashes unless --reveal is passed. The hashes are keyed randomly on
+each run, so they can't be used to recover short secrets.
+
+The paths can be in different regions or accounts by passing --source-region,
+--target-region, --source-role and --target-role.
+
+example:
+	pstore diff /app/staging /app/prod`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(pstore.UsageError)
+		}
+
+		flags := cmd.Flags()
+		opts := diffOptions{}
+		opts.sourceRegion, _ = flags.GetString("source-region")
+		opts.sourceRole, _ = flags.GetString("source-role")
+		opts.targetRegion, _ = flags.GetString("target-region")
+		opts.targetRole, _ = flags.GetString("target-role")
+		opts.reveal, _ = flags.GetBool("reveal")
+		opts.json, _ = flags.GetBool("json")
+		opts.exitCode, _ = flags.GetBool("exit-code")
+
+		diff(args[0], args[1], opts)
+	},
+}
+
+type diffOptions struct {
+	sourceRegion string
+	sourceRole   string
+	targetRegion string
+	targetRole   string
+	reveal       bool
+	json         bool
+	exitCode     bool
+}
+
+func diff(sourcePath, targetPath string, opts diffOptions) {
+	source, err := pstore.GetParameterTree(pstore.NewSessionFor(opts.sourceRegion, opts.sourceRole), sourcePath, true, true)
+	if err != nil {
+		pstore.Abort(pstore.PstoreError, err)
+	}
+
+	target, err := pstore.GetParameterTree(pstore.NewSessionFor(opts.targetRegion, opts.targetRole), targetPath, true, true)
+	if err != nil {
+		pstore.Abort(pstore.PstoreError, err)
+	}
+
+	entries := pstore.DiffTrees(sourcePath, source, targetPath, target)
+
+	if !opts.reveal {
+		hash := pstore.NewValueHasher()
+		for idx := range entries {
+			entry := &entries[idx]
+			if entry.SourceType != "" {
+				entry.SourceValue = hash(entry.SourceValue)
+			}
+			if entry.TargetType != "" {
+				entry.TargetValue = hash(entry.TargetValue)
+			}
+		}
+	}
+
+	if opts.json {
+		bytes, _ := json.MarshalIndent(entries, "", "  ")
+		fmt.Println(string(bytes))
+	} else {
+		printDiff(entries)
+	}
+
+	if opts.exitCode && len(entries) > 0 {
+		os.Exit(1)
+	}
+}
+
+func printDiff(entries []pstore.DiffEntry) {
+	green := color.New(color.FgGreen).SprintfFunc()
+	red := color.New(color.FgRed).SprintfFunc()
+	yellow := color.New(color.FgYellow).SprintfFunc()
+
+	for _, entry := range entries {
+		switch entry.Status {
+		case pstore.DiffAdded:
+			fmt.Println(green("+ %s (%s) %s", entry.Key, entry.TargetType, entry.TargetValue))
+		case pstore.DiffRemoved:
+			fmt.Println(red("- %s (%s) %s", entry.Key, entry.SourceType, entry.SourceValue))
+		case pstore.DiffChanged:
+			fmt.Println(yellow("~ %s", entry.Key))
+			if entry.TypeChanged {
+				fmt.Printf("    type:  %s → %s\n", entry.SourceType, entry.TargetType)
+			}
+			if entry.ValueChanged {
+				fmt.Printf("    value: %s → %s\n", entry.SourceValue, entry.TargetValue)
+			}
+		}
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().String("source-region", "", "Region of the source path (defaults to the usual region)")
+	diffCmd.Flags().String("source-role", "", "Role to assume to read the source path")
+	diffCmd.Flags().String("target-region", "", "Region of the target path (defaults to the usual region)")
+	diffCmd.Flags().String("target-role", "", "Role to assume to read the target path")
+	diffCmd.Flags().Bool("reveal", false, "Show values instead of hashes")
+	diffCmd.Flags().BoolP("json", "j", false, "Emit JSON instead of text")
+	diffCmd.Flags().Bool("exit-code", false, "Exit with status 1 if there are any differences")
+}
@@ -0,0 +1,54 @@
+// Copyright © 2017 Aidan Steele <aidan.steele@glassechidna.com.au>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/glassechidna/pstore/pkg/pstore"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnoses region, credential and permission problems",
+	Long: `
+Reports how the AWS region was resolved, where credentials are coming from and
+who they belong to. Then tests that every parameter, path and tag referenced
+in the environment can actually be read and decrypted, explaining which
+permission is missing for any that can't.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		doctor(pstoreOptions())
+	},
+}
+
+func doctor(opts pstore.Options) {
+	ok := pstore.Diagnose(opts, func(check pstore.Check) {
+		if check.OK {
+			color.Green("✔ %s: %s", check.Name, check.Detail)
+		} else {
+			color.Red("✗ %s: %s", check.Name, check.Detail)
+		}
+	})
+
+	if !ok {
+		os.Exit(pstore.PstoreError)
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(doctorCmd)
+}
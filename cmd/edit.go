@@ -0,0 +1,186 @@
+// Copyright © 2017 Aidan Steele <aidan.steele@glassechidna.com.au>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/fatih/color"
+	"github.com/glassechidna/pstore/pkg/pstore"
+	"github.com/spf13/cobra"
+)
+
+var editCmd = &cobra.Command{
+	Use:   "edit <path>",
+	Short: "Edits every parameter under a path in $EDITOR",
+	Long: `
+Opens the parameters under a path in $EDITOR as a YAML document keyed by name
+relative to the path. Once the editor is closed the changes are listed and,
+once confirmed, applied: changed keys are updated, new keys are created and
+removed keys are deleted. Existing parameters keep their type and KMS key, new
+ones are typed by the same rules as import.
+
+The decrypted values are written to a temporary file only you can read, which
+is always deleted afterwards.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.Usage()
+			os.Exit(pstore.UsageError)
+		}
+
+		rules, _ := cmd.Flags().GetStringArray("rule")
+		typeRules, err := pstore.ParseTypeRules(rules)
+		if err != nil {
+			pstore.Abort(pstore.UsageError, err)
+		}
+
+		edit(args[0], typeRules, pstoreOptions().Verbose)
+	},
+}
+
+func edit(path string, rules []pstore.TypeRule, verbose bool) {
+	sess := pstore.NewSession()
+
+	params, err := pstore.GetParameterTree(sess, path, true, true)
+	if err != nil {
+		pstore.Abort(pstore.PstoreError, err)
+	}
+
+	metadata, err := pstore.DescribeParameterTree(sess, path, true)
+	if err != nil {
+		pstore.Abort(pstore.PstoreError, err)
+	}
+
+	original, err := pstore.EditDocument(path, params)
+	if err != nil {
+		pstore.Abort(pstore.PstoreError, err)
+	}
+
+	edited := editInEditor(original)
+
+	changes, deletes, err := pstore.PlanEdit(path, params, metadata, edited, rules)
+	if err != nil {
+		pstore.Abort(pstore.UsageError, err)
+	}
+
+	anything := printChanges(changes, verbose)
+	for _, name := range deletes {
+		color.New(color.FgRed).Fprintf(os.Stderr, "- %s\n", name)
+		anything = true
+	}
+
+	if !anything {
+		fmt.Fprintln(os.Stderr, "Nothing to do")
+		return
+	}
+
+	confirm(false, "y", "Apply these changes? [y/N] ")
+	applyChangesAndDeletes(sess, changes, deletes)
+}
+
+// editInEditor opens contents in the user's editor and returns what they
+// saved. The temporary file is removed however pstore exits.
+func editInEditor(contents []byte) []byte {
+	file, err := ioutil.TempFile("", "pstore-edit-*.yaml")
+	if err != nil {
+		pstore.Abort(pstore.UsageError, err)
+	}
+
+	cleanup := func() {
+		os.Remove(file.Name())
+	}
+	defer cleanup()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, pstore.ExitSignals...)
+	defer signal.Stop(sigs)
+	go func() {
+		if _, ok := <-sigs; ok {
+			cleanup()
+			os.Exit(pstore.UsageError)
+		}
+	}()
+
+	_, err = file.Write(contents)
+	file.Close()
+	if err != nil {
+		cleanup()
+		pstore.Abort(pstore.UsageError, err)
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	args := append(strings.Fields(editor), file.Name())
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		cleanup()
+		pstore.Abort(pstore.UsageError, fmt.Sprintf("editor failed, nothing was changed: %s", err))
+	}
+
+	edited, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		cleanup()
+		pstore.Abort(pstore.UsageError, err)
+	}
+
+	return edited
+}
+
+// applyChangesAndDeletes makes the puts and then the deletes, reporting a
+// summary and exiting non-zero if anything failed.
+func applyChangesAndDeletes(sess *session.Session, changes []pstore.Change, deletes []string) {
+	failed := 0
+
+	pstore.ApplyChanges(sess, changes, 1, 3, func(change pstore.Change, err error) {
+		if err != nil {
+			reportFailedChange(change, err)
+			failed++
+		}
+	})
+
+	pstore.DeleteParameters(sess, deletes, func(name string, err error) {
+		if err != nil {
+			color.New(color.FgRed).Fprintf(os.Stderr, "✗ Failed to delete %s: %s\n", name, err)
+			failed++
+		}
+	})
+
+	if failed > 0 {
+		os.Exit(pstore.PstoreError)
+	}
+
+	color.New(color.FgGreen).Fprintln(os.Stderr, "✔ All changes applied")
+}
+
+func init() {
+	RootCmd.AddCommand(editCmd)
+	editCmd.Flags().StringArray("rule", pstore.DefaultTypeRules, "Type rule for new keys in GLOB=TYPE form, may be repeated. The first match wins")
+}
@@ -0,0 +1,69 @@
+// Copyright © 2017 Aidan Steele <aidan.steele@glassechidna.com.au>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/glassechidna/pstore/pkg/pstore"
+	"github.com/spf13/cobra"
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Prints the parameters exec would set in any registered format or a Go template",
+	Long: `
+Resolves parameters from the environment the same way exec does and prints
+them in the given --format, or by executing a Go --template once for each of
+them. Templates can use .Key, .Value, .Name, .Source (name, path or tag),
+.Version and .Type, along with the json, shquote, upper and lower functions.
+
+example:
+	PSTORE_DBSTRING=MyDatabaseString pstore env --format systemd > app.env
+	PSTORE_DBSTRING=MyDatabaseString pstore env --template '{{.Key}}={{json .Value}}'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		text, _ := cmd.Flags().GetString("template")
+
+		if text != "" && cmd.Flags().Changed("format") {
+			pstore.Abort(pstore.UsageError, "--format and --template can't be combined")
+		}
+
+		formatter, err := pstore.LookupFormatter(format)
+		if text != "" {
+			formatter, err = pstore.TemplateFormatter(text)
+		}
+		if err != nil {
+			pstore.Abort(pstore.UsageError, err)
+		}
+
+		writeFormatted(pstoreOptions(), formatter)
+	},
+}
+
+// writeFormatted resolves the parameters requested by the environment and
+// writes them to stdout with formatter.
+func writeFormatted(opts pstore.Options, formatter pstore.Formatter) {
+	if err := formatter(os.Stdout, pstore.Resolve(opts)); err != nil {
+		pstore.Abort(pstore.UsageError, err)
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(envCmd)
+	envCmd.Flags().String("format", pstore.FormatDotenv, strings.Join(pstore.FormatterNames(), ", "))
+	envCmd.Flags().String("template", "", "Go template to execute for each parameter")
+}
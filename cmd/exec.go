@@ -16,10 +16,10 @@ package cmd
 
 import (
 	"os"
+	"time"
 
 	"github.com/glassechidna/pstore/pkg/pstore"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 var execCmd = &cobra.Command{
@@ -30,23 +30,51 @@ var execCmd = &cobra.Command{
 	val is SomeSuperSecretDbString`,
 
 	Run: func(cmd *cobra.Command, args []string) {
-		simplePrefix := viper.GetString("prefix")
-		tagPrefix := viper.GetString("tag-prefix")
-		pathPrefix := viper.GetString("path-prefix")
-		verbose := viper.GetBool("verbose")
+		interval, _ := cmd.Flags().GetDuration("watch")
+		redact, _ := cmd.Flags().GetBool("redact")
 
-		doExec(simplePrefix, tagPrefix, pathPrefix, verbose, args)
+		if interval > 0 || redact {
+			debounce, _ := cmd.Flags().GetDuration("watch-debounce")
+			signalName, _ := cmd.Flags().GetString("watch-signal")
+			noRedact, _ := cmd.Flags().GetStringSlice("no-redact")
+
+			supervisor := &pstore.Supervisor{
+				Options:       pstoreOptions(),
+				Args:          args,
+				Interval:      interval,
+				Debounce:      debounce,
+				Redact:        redact,
+				RedactExclude: noRedact,
+			}
+
+			if signalName != "" {
+				sig, err := pstore.ParseSignal(signalName)
+				if err != nil {
+					pstore.Abort(pstore.UsageError, err)
+				}
+				supervisor.Signal = sig
+			}
+
+			supervisor.Run()
+		}
+
+		doExec(pstoreOptions(), args)
 	},
 }
 
-func doExec(simplePrefix, tagPrefix, pathPrefix string, verbose bool, args []string) {
-	pstore.Doit(simplePrefix, tagPrefix, pathPrefix, verbose, func(key, val string) {
-		os.Setenv(key, val)
-	})
+func doExec(opts pstore.Options, args []string) {
+	for _, param := range pstore.Resolve(opts) {
+		os.Setenv(param.EnvName, param.Value)
+	}
 
 	pstore.ExecCommand(args)
 }
 
 func init() {
 	RootCmd.AddCommand(execCmd)
+	execCmd.Flags().Duration("watch", 0, "Poll parameters at this interval and act on the child when they change")
+	execCmd.Flags().String("watch-signal", "", "Signal to send the child when parameters change (e.g. HUP) instead of restarting it")
+	execCmd.Flags().Duration("watch-debounce", 5*time.Second, "Wait this long after a change before signalling or restarting the child")
+	execCmd.Flags().Bool("redact", false, "Replace SecureString values in the child's stdout and stderr with ***")
+	execCmd.Flags().StringSlice("no-redact", nil, "Env var names whose values should not be redacted")
 }
@@ -0,0 +1,80 @@
+// Copyright © 2017 Aidan Steele <aidan.steele@glassechidna.com.au>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/fatih/color"
+	"github.com/glassechidna/pstore/pkg/pstore"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export <path>",
+	Short: "Writes all parameters under a path as dotenv, JSON, YAML, docker env-file or Java properties",
+	Long: `
+Writes every parameter under a path to stdout in the given format. Variables
+are named the same way exec names PSTOREPATH_ parameters, i.e. by the last
+segment of their name, and each format is escaped as it requires.
+
+example:
+	pstore export /app/dev --format dotenv > .env`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.Usage()
+			os.Exit(pstore.UsageError)
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		excludeSecure, _ := cmd.Flags().GetBool("exclude-secure")
+		export(args[0], format, excludeSecure)
+	},
+}
+
+func export(path, format string, excludeSecure bool) {
+	params, err := pstore.GetParameterTree(pstore.NewSession(), path, true, !excludeSecure)
+	if err != nil {
+		pstore.Abort(pstore.PstoreError, err)
+	}
+
+	vars := []pstore.EnvVar{}
+	names := map[string]string{}
+
+	for _, param := range params {
+		if excludeSecure && *param.Type == ssm.ParameterTypeSecureString {
+			continue
+		}
+
+		name := pstore.PathEnvName(*param.Name)
+		if other, ok := names[name]; ok {
+			color.New(color.FgYellow).Fprintf(os.Stderr, "%s and %s are both exported as %s\n", other, *param.Name, name)
+		}
+		names[name] = *param.Name
+
+		vars = append(vars, pstore.EnvVar{Name: name, Value: *param.Value})
+	}
+
+	if err := pstore.WriteEnv(os.Stdout, format, vars); err != nil {
+		pstore.Abort(pstore.UsageError, err)
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().String("format", pstore.FormatDotenv, "dotenv, json, yaml, docker-env or properties")
+	exportCmd.Flags().Bool("exclude-secure", false, "Leave SecureString parameters out")
+}
@@ -0,0 +1,116 @@
+// Copyright © 2017 Aidan Steele <aidan.steele@glassechidna.com.au>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/glassechidna/pstore/pkg/pstore"
+	"github.com/spf13/cobra"
+)
+
+var getCmd = &cobra.Command{
+	Use:   "get <name>",
+	Short: "Prints the raw value of a single parameter",
+	Long: `
+Prints the decrypted value of a parameter exactly, without a trailing newline,
+for use in scripts. --version or --label read an older version of it, as does
+a name:version or name:label selector. --base64 decodes the value first, and
+--json prints it along with its metadata instead.
+
+The exit status says why reading the parameter failed:
+	66	the parameter or version doesn't exist
+	77	access to the parameter was denied
+	65	the value couldn't be decrypted
+	69	any other parameter store error
+
+example:
+	DB_PASSWORD=$(pstore get /app/prod/DB_PASSWORD)`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.Usage()
+			os.Exit(pstore.UsageError)
+		}
+
+		version, _ := cmd.Flags().GetInt64("version")
+		label, _ := cmd.Flags().GetString("label")
+		decode, _ := cmd.Flags().GetBool("base64")
+		jsonFormat, _ := cmd.Flags().GetBool("json")
+
+		name, err := pstore.Selector(args[0], version, label)
+		if err != nil {
+			pstore.Abort(pstore.UsageError, err)
+		}
+
+		get(name, decode, jsonFormat)
+	},
+}
+
+// getResult is a parameter as printed by get --json.
+type getResult struct {
+	Name             string    `json:"name"`
+	Value            string    `json:"value"`
+	Type             string    `json:"type"`
+	Version          int64     `json:"version"`
+	Selector         string    `json:"selector,omitempty"`
+	LastModifiedDate time.Time `json:"last_modified_date"`
+	DataType         string    `json:"data_type,omitempty"`
+	ARN              string    `json:"arn"`
+}
+
+func get(name string, decode, jsonFormat bool) {
+	param, err := pstore.GetParameter(pstore.NewSession(), name, true)
+	if err != nil {
+		pstore.Abort(pstore.ExitStatus(err), err)
+	}
+
+	value := []byte(*param.Value)
+	if decode {
+		value, err = base64.StdEncoding.DecodeString(*param.Value)
+		if err != nil {
+			pstore.Abort(pstore.PstoreError, fmt.Sprintf("%s isn't valid base64: %s", name, err))
+		}
+	}
+
+	if !jsonFormat {
+		os.Stdout.Write(value)
+		return
+	}
+
+	bytes, _ := json.MarshalIndent(getResult{
+		Name:             *param.Name,
+		Value:            string(value),
+		Type:             *param.Type,
+		Version:          *param.Version,
+		Selector:         aws.StringValue(param.Selector),
+		LastModifiedDate: aws.TimeValue(param.LastModifiedDate),
+		DataType:         aws.StringValue(param.DataType),
+		ARN:              aws.StringValue(param.ARN),
+	}, "", "  ")
+	fmt.Println(string(bytes))
+}
+
+func init() {
+	RootCmd.AddCommand(getCmd)
+	getCmd.Flags().Int64("version", 0, "Read this version of the parameter")
+	getCmd.Flags().String("label", "", "Read the version of the parameter with this label")
+	getCmd.Flags().Bool("base64", false, "Decode the value from base64")
+	getCmd.Flags().BoolP("json", "j", false, "Print the value and its metadata as JSON")
+}
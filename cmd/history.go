@@ -0,0 +1,124 @@
+// Copyright © 2017 Aidan Steele <aidan.steele@glassechidna.com.au>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/fatih/color"
+	"github.com/glassechidna/pstore/pkg/pstore"
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history <name>",
+	Short: "Shows every version of a parameter and what changed between them",
+	Long: `
+Lists every version of a parameter with when it was modified, by whom and its
+labels, followed by what changed since the previous version. Values are masked
+unless --reveal is passed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.Usage()
+			os.Exit(pstore.UsageError)
+		}
+
+		reveal, _ := cmd.Flags().GetBool("reveal")
+		history(args[0], reveal)
+	},
+}
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback <name> --to <version>",
+	Short: "Writes an old version of a parameter back as a new version",
+	Long: `
+Writes the value of an old version of a parameter back as a new version, along
+with the type, KMS key, description and allowed pattern it had at the time.
+
+example:
+	pstore rollback /app/prod/API_URL --to 3`,
+	Run: func(cmd *cobra.Command, args []string) {
+		version, _ := cmd.Flags().GetInt64("to")
+		if len(args) != 1 || version < 1 {
+			cmd.Usage()
+			os.Exit(pstore.UsageError)
+		}
+
+		newVersion, err := pstore.Rollback(pstore.NewSession(), args[0], version)
+		if err != nil {
+			pstore.Abort(pstore.PstoreError, err)
+		}
+
+		color.New(color.FgGreen).Fprintf(os.Stderr, "✔ Rolled %s back to version %d as version %d\n", args[0], version, newVersion)
+	},
+}
+
+func history(name string, reveal bool) {
+	versions, err := pstore.GetParameterHistory(pstore.NewSession(), name, true)
+	if err != nil {
+		pstore.Abort(pstore.PstoreError, err)
+	}
+
+	show := func(value string) string {
+		if reveal {
+			return strconv.Quote(value)
+		}
+		return maskValue(value, true)
+	}
+
+	var previous *ssm.ParameterHistory
+
+	for _, version := range versions {
+		labels := ""
+		if len(version.Labels) > 0 {
+			labels = "  labels: " + strings.Join(aws.StringValueSlice(version.Labels), ", ")
+		}
+
+		fmt.Println(color.New(color.Bold).Sprintf("version %d", *version.Version) + faint("  %s  %s", version.LastModifiedDate.Format("2006-01-02 15:04:05 MST"), aws.StringValue(version.LastModifiedUser)) + labels)
+
+		if previous == nil {
+			fmt.Printf("    value: %s\n", show(*version.Value))
+		} else {
+			if *previous.Value != *version.Value {
+				fmt.Printf("    value: %s → %s\n", show(*previous.Value), show(*version.Value))
+			}
+			printFieldChange("type", previous.Type, version.Type)
+			printFieldChange("key", previous.KeyId, version.KeyId)
+			printFieldChange("tier", previous.Tier, version.Tier)
+			printFieldChange("description", previous.Description, version.Description)
+			printFieldChange("allowed pattern", previous.AllowedPattern, version.AllowedPattern)
+		}
+
+		previous = version
+	}
+}
+
+func printFieldChange(field string, before, after *string) {
+	if aws.StringValue(before) != aws.StringValue(after) {
+		fmt.Printf("    %s: %s → %s\n", field, aws.StringValue(before), aws.StringValue(after))
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(historyCmd)
+	RootCmd.AddCommand(rollbackCmd)
+	historyCmd.Flags().Bool("reveal", false, "Show values instead of masking them")
+	rollbackCmd.Flags().Int64("to", 0, "Version to roll back to")
+}
@@ -0,0 +1,74 @@
+// Copyright © 2017 Aidan Steele <aidan.steele@glassechidna.com.au>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/glassechidna/pstore/pkg/pstore"
+	"github.com/spf13/cobra"
+)
+
+var iamPolicyCmd = &cobra.Command{
+	Use:   "iam-policy",
+	Short: "Generates a least-privilege IAM policy for the current references",
+	Long: `
+Emits an IAM policy document granting exactly what pstore needs to resolve the
+references in the environment, or in a manifest file passed with --manifest:
+
+  names:
+    DBSTRING: MyDatabaseString
+  paths:
+    - /app/prod
+  tags:
+    env: prod
+
+Named parameters and paths are granted by exact ARN, tagged parameters by a
+tag condition and kms:Decrypt is granted on the keys the SecureStrings use.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		manifest, _ := cmd.Flags().GetString("manifest")
+		iamPolicy(pstoreOptions(), manifest)
+	},
+}
+
+func iamPolicy(opts pstore.Options, manifest string) {
+	req := pstore.GetParamRequestFromEnv(opts.SimplePrefix, opts.TagPrefix, opts.PathPrefix)
+
+	if manifest != "" {
+		var err error
+		req, err = pstore.ReadManifest(manifest)
+		if err != nil {
+			pstore.Abort(pstore.UsageError, err)
+		}
+	}
+
+	if req.Empty() {
+		pstore.Abort(pstore.UsageError, "no parameter references to generate a policy for")
+	}
+
+	doc, err := pstore.Policy(pstore.NewSession(), req)
+	if err != nil {
+		pstore.Abort(pstore.PstoreError, err)
+	}
+
+	bytes, _ := json.MarshalIndent(doc, "", "  ")
+	fmt.Println(string(bytes))
+}
+
+func init() {
+	RootCmd.AddCommand(iamPolicyCmd)
+	iamPolicyCmd.Flags().String("manifest", "", "Read references from this YAML or JSON file instead of the environment")
+}
@@ -0,0 +1,254 @@
+// Copyright © 2017 Aidan Steele <aidan.steele@glassechidna.com.au>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/fatih/color"
+	"github.com/glassechidna/pstore/pkg/pstore"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Creates or updates parameters under a path from a dotenv, JSON or YAML file",
+	Long: `
+Pushes every key in a file into Parameter Store under the given path. The
+changes are listed before anything is written, and --dry-run stops there.
+
+New keys are stored as Strings unless they match a --rule, e.g. the default
+rules make *_PASSWORD, *_SECRET, *_TOKEN and *_KEY SecureStrings, and
+SecureStrings are encrypted with --key-id. Existing parameters keep their type,
+KMS key and other settings, only their value is updated.
+
+example:
+	pstore import --path /app/prod --format dotenv .env`,
+	Run: func(cmd *cobra.Command, args []string) {
+		flags := cmd.Flags()
+		path, _ := flags.GetString("path")
+
+		if len(args) != 1 || path == "" {
+			cmd.Usage()
+			os.Exit(pstore.UsageError)
+		}
+
+		format, _ := flags.GetString("format")
+		if format == "" {
+			format = formatForFile(args[0])
+		}
+
+		rules, _ := flags.GetStringArray("rule")
+		typeRules, err := pstore.ParseTypeRules(rules)
+		if err != nil {
+			pstore.Abort(pstore.UsageError, err)
+		}
+
+		opts := importOptions{
+			path:    path,
+			format:  format,
+			rules:   typeRules,
+			verbose: pstoreOptions().Verbose,
+		}
+		opts.defaultType, _ = flags.GetString("default-type")
+		opts.keyID, _ = flags.GetString("key-id")
+		opts.dryRun, _ = flags.GetBool("dry-run")
+		opts.yes, _ = flags.GetBool("yes")
+		opts.concurrency, _ = flags.GetInt("concurrency")
+		opts.rate, _ = flags.GetInt("rate")
+
+		doImport(args[0], opts)
+	},
+}
+
+type importOptions struct {
+	path        string
+	format      string
+	rules       []pstore.TypeRule
+	defaultType string
+	keyID       string
+	dryRun      bool
+	yes         bool
+	concurrency int
+	rate        int
+	verbose     bool
+}
+
+func formatForFile(path string) string {
+	switch filepath.Ext(path) {
+	case ".json":
+		return pstore.FormatJSON
+	case ".yaml", ".yml":
+		return pstore.FormatYAML
+	}
+	return pstore.FormatDotenv
+}
+
+func doImport(file string, opts importOptions) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		pstore.Abort(pstore.UsageError, err)
+	}
+
+	values, err := pstore.ParseEnvFile(opts.format, data)
+	if err != nil {
+		pstore.Abort(pstore.UsageError, fmt.Sprintf("%s: %s", file, err))
+	}
+
+	sess := pstore.NewSession()
+	existing, err := pstore.GetParameterTree(sess, opts.path, true, true)
+	if err != nil {
+		pstore.Abort(pstore.PstoreError, err)
+	}
+
+	metadata, err := pstore.DescribeParameterTree(sess, opts.path, true)
+	if err != nil {
+		pstore.Abort(pstore.PstoreError, err)
+	}
+
+	current := map[string]*ssm.Parameter{}
+	for _, param := range existing {
+		current[*param.Name] = param
+	}
+
+	changes := []pstore.Change{}
+	for key, value := range values {
+		put := pstore.PutRequest{
+			Name:  pstore.JoinName(opts.path, key),
+			Value: value,
+			Type:  pstore.TypeFor(opts.rules, key, opts.defaultType),
+		}
+
+		if param, ok := current[put.Name]; ok {
+			// rules only decide how new keys are stored, an update mustn't
+			// quietly change the type or KMS key of an existing parameter
+			put.Type = *param.Type
+		} else if put.Type == ssm.ParameterTypeSecureString {
+			put.KeyID = opts.keyID
+		}
+
+		if meta, ok := metadata[put.Name]; ok {
+			put.KeyID = aws.StringValue(meta.KeyId)
+			put.Tier = aws.StringValue(meta.Tier)
+			put.Description = aws.StringValue(meta.Description)
+			put.AllowedPattern = aws.StringValue(meta.AllowedPattern)
+		}
+
+		changes = append(changes, pstore.PlanChange(put, current[put.Name], metadata[put.Name], nil))
+	}
+
+	if !printChanges(changes, opts.verbose) {
+		fmt.Fprintln(os.Stderr, "Nothing to do")
+		return
+	}
+
+	if opts.dryRun {
+		return
+	}
+
+	confirm(opts.yes, "y", "Apply these changes? [y/N] ")
+	applyChanges(sess, changes, opts.concurrency, opts.rate)
+}
+
+// printChanges lists planned changes in name order on stderr, returning
+// whether there's anything to do.
+func printChanges(changes []pstore.Change, verbose bool) bool {
+	sortChanges(changes)
+
+	green := color.New(color.FgGreen)
+	yellow := color.New(color.FgYellow)
+	anything := false
+
+	for _, change := range changes {
+		switch change.Action {
+		case pstore.ActionCreate:
+			green.Fprintf(os.Stderr, "+ %s (%s)\n", change.Put.Name, change.Put.Type)
+			anything = true
+		case pstore.ActionUpdate:
+			yellow.Fprintf(os.Stderr, "~ %s (%s)\n", change.Put.Name, change.Detail)
+			anything = true
+		default:
+			if verbose {
+				fmt.Fprintf(os.Stderr, "  %s\n", change.Put.Name)
+			}
+		}
+	}
+
+	return anything
+}
+
+// applyChanges makes the changes, reporting each failure and a summary of
+// what happened. It exits non-zero if anything failed.
+func applyChanges(sess *session.Session, changes []pstore.Change, concurrency, rate int) {
+	counts := map[string]int{}
+	failed := 0
+
+	for _, change := range changes {
+		if change.Action == pstore.ActionUnchanged {
+			counts[change.Action]++
+		}
+	}
+
+	pstore.ApplyChanges(sess, changes, concurrency, rate, func(change pstore.Change, err error) {
+		if err != nil {
+			reportFailedChange(change, err)
+			failed++
+			return
+		}
+		counts[change.Action]++
+	})
+
+	fmt.Fprintf(os.Stderr, "%d created, %d updated, %d unchanged, %d failed\n", counts[pstore.ActionCreate], counts[pstore.ActionUpdate], counts[pstore.ActionUnchanged], failed)
+
+	if failed > 0 {
+		os.Exit(pstore.PstoreError)
+	}
+}
+
+func sortChanges(changes []pstore.Change) {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Put.Name < changes[j].Put.Name
+	})
+}
+
+// reportFailedChange explains why change failed, making clear when the
+// value was written and only tagging it went wrong.
+func reportFailedChange(change pstore.Change, err error) {
+	if _, ok := err.(*pstore.TagError); ok {
+		color.New(color.FgRed).Fprintf(os.Stderr, "✗ %s\n", err)
+		return
+	}
+	color.New(color.FgRed).Fprintf(os.Stderr, "✗ Failed to %s %s: %s\n", change.Action, change.Put.Name, err)
+}
+
+func init() {
+	RootCmd.AddCommand(importCmd)
+	importCmd.Flags().String("path", "", "Parameter path to import into, e.g. /app/prod")
+	importCmd.Flags().String("format", "", "dotenv, json or yaml (defaults to guessing from the file extension)")
+	importCmd.Flags().StringArray("rule", pstore.DefaultTypeRules, "Type rule for new keys in GLOB=TYPE form, may be repeated. The first match wins")
+	importCmd.Flags().String("default-type", ssm.ParameterTypeString, "Type of new keys that match no rule")
+	importCmd.Flags().String("key-id", "", "KMS key to encrypt new SecureStrings with (defaults to alias/aws/ssm)")
+	importCmd.Flags().Bool("dry-run", false, "Only show what would change")
+	importCmd.Flags().Bool("yes", false, "Don't ask for confirmation")
+	importCmd.Flags().Int("concurrency", 2, "Maximum number of parameters written at once")
+	importCmd.Flags().Int("rate", 3, "Maximum number of parameters written per second")
+}
@@ -0,0 +1,123 @@
+// Copyright © 2017 Aidan Steele <aidan.steele@glassechidna.com.au>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/glassechidna/pstore/pkg/pstore"
+	"github.com/spf13/cobra"
+)
+
+var labelCmd = &cobra.Command{
+	Use:   "label <name> <label>...",
+	Short: "Labels a version of a parameter",
+	Long: `
+Attaches labels to a version of a parameter, the latest unless --version is
+passed. A label can only be on one version of a parameter at a time, so
+labelling a version moves the label from wherever it was before.
+
+example:
+	pstore label /app/prod/API_URL --version 3 release-2020-10`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			cmd.Usage()
+			os.Exit(pstore.UsageError)
+		}
+
+		version, _ := cmd.Flags().GetInt64("version")
+		labelled, err := pstore.LabelParameter(pstore.NewSession(), args[0], version, args[1:])
+		if err != nil {
+			pstore.Abort(pstore.PstoreError, err)
+		}
+
+		color.New(color.FgGreen).Fprintf(os.Stderr, "✔ Labelled %s version %d\n", args[0], labelled)
+	},
+}
+
+var unlabelCmd = &cobra.Command{
+	Use:   "unlabel <name> <label>...",
+	Short: "Removes labels from a parameter",
+	Long: `
+Removes labels from a parameter. They're removed from whichever version they
+are on, unless --version is passed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			cmd.Usage()
+			os.Exit(pstore.UsageError)
+		}
+
+		version, _ := cmd.Flags().GetInt64("version")
+		if err := pstore.UnlabelParameter(pstore.NewSession(), args[0], version, args[1:]); err != nil {
+			pstore.Abort(pstore.PstoreError, err)
+		}
+
+		color.New(color.FgGreen).Fprintf(os.Stderr, "✔ Unlabelled %s\n", args[0])
+	},
+}
+
+var labelPathCmd = &cobra.Command{
+	Use:   "label-path <path> <label>",
+	Short: "Labels the current version of every parameter under a path",
+	Long: `
+Puts a label on the current version of every parameter under a path, marking
+a consistent config release across the whole tree.
+
+example:
+	pstore label-path /app/prod release-2020-10`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(pstore.UsageError)
+		}
+
+		labelPath(args[0], args[1], pstoreOptions().Verbose)
+	},
+}
+
+func labelPath(path, label string, verbose bool) {
+	labelled, failed := 0, 0
+
+	err := pstore.LabelPath(pstore.NewSession(), path, label, func(name string, version int64, err error) {
+		if err != nil {
+			color.New(color.FgRed).Fprintf(os.Stderr, "✗ Failed to label %s version %d: %s\n", name, version, err)
+			failed++
+			return
+		}
+		if verbose {
+			color.New(color.FgGreen).Fprintf(os.Stderr, "✔ Labelled %s version %d\n", name, version)
+		}
+		labelled++
+	})
+	if err != nil {
+		pstore.Abort(pstore.PstoreError, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "%d labelled, %d failed\n", labelled, failed)
+
+	if failed > 0 {
+		os.Exit(pstore.PstoreError)
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(labelCmd)
+	RootCmd.AddCommand(unlabelCmd)
+	RootCmd.AddCommand(labelPathCmd)
+	labelCmd.Flags().Int64("version", 0, "Version to label (defaults to the latest)")
+	unlabelCmd.Flags().Int64("version", 0, "Version to remove the labels from (defaults to wherever they are)")
+}
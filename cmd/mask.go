@@ -0,0 +1,29 @@
+// Copyright © 2017 Aidan Steele <aidan.steele@glassechidna.com.au>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+// maskValue hides a value that shouldn't be shown in full. partial leaves
+// the first and last characters visible, for long enough values, to help
+// tell masked values apart.
+func maskValue(value string, partial bool) string {
+	const mask = "****"
+
+	runes := []rune(value)
+	if !partial || len(runes) < 8 {
+		return mask
+	}
+
+	return string(runes[0]) + mask + string(runes[len(runes)-1])
+}
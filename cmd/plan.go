@@ -0,0 +1,87 @@
+// Copyright © 2017 Aidan Steele <aidan.steele@glassechidna.com.au>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/glassechidna/pstore/pkg/pstore"
+	"github.com/spf13/cobra"
+)
+
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Shows which env vars exec would set, without decrypting anything",
+	Long: `
+Parses the environment the same way exec does and lists every env var that
+would be set, along with where it comes from. Each parameter is checked for
+existence, path and tag references are expanded, and invalid or colliding
+env var names are flagged. No secret values are ever printed.
+
+Exits non-zero if any problems were found.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		plan(pstoreOptions())
+	},
+}
+
+func plan(opts pstore.Options) {
+	req := pstore.GetParamRequestFromEnv(opts.SimplePrefix, opts.TagPrefix, opts.PathPrefix)
+	if req.Empty() {
+		fmt.Fprintf(os.Stderr, "No env vars with %s, %s or %s prefixes found\n", opts.SimplePrefix, opts.TagPrefix, opts.PathPrefix)
+		return
+	}
+
+	planned, err := pstore.Plan(pstore.NewSession(), req)
+	if err != nil {
+		pstore.Abort(pstore.PstoreError, err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ENV VAR\tSOURCE\tPARAMETER\tTYPE\tVERSION\tSTATUS")
+
+	ok := true
+	for _, param := range planned {
+		status := "ok"
+		if !param.OK() {
+			status = strings.Join(param.Problems, "; ")
+			ok = false
+		}
+
+		version := ""
+		if param.Version > 0 {
+			version = fmt.Sprintf("%d", param.Version)
+		}
+
+		source := param.Source
+		if param.Source != pstore.SourceName {
+			source = fmt.Sprintf("%s (%s)", param.Source, param.Reference)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", param.EnvName, source, param.ParamName, param.Type, version, status)
+	}
+
+	w.Flush()
+
+	if !ok {
+		os.Exit(pstore.PstoreError)
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(planCmd)
+}
@@ -17,11 +17,8 @@ package cmd
 import (
 	"fmt"
 
-	"strings"
-
 	"github.com/glassechidna/pstore/pkg/pstore"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 var powershellCmd = &cobra.Command{
@@ -32,24 +29,52 @@ var powershellCmd = &cobra.Command{
 	$Cmd = (pstore powershell mycompany-prod) | Out-String
 	Invoke-Expression $Cmd
 	Do-SomethingWith -DbString $DBSTRING
+
+Values are written as single-quoted literals, so nothing in them is expanded.
+--format ps1 writes a UTF-8 file to dot-source instead, and --format cmd
+writes set statements for a cmd.exe batch file:
+	pstore powershell --format ps1 > env.ps1
+	pstore powershell --format cmd > env.bat
+
+--unset prints statements removing the same env vars instead, without
+decrypting anything.
 	`,
 	Run: func(cmd *cobra.Command, args []string) {
-		simplePrefix := viper.GetString("prefix")
-		tagPrefix := viper.GetString("tag-prefix")
-		pathPrefix := viper.GetString("path-prefix")
-		verbose := viper.GetBool("verbose")
-
-		doPowershell(simplePrefix, tagPrefix, pathPrefix, verbose)
+		format, _ := cmd.Flags().GetString("format")
+		unset, _ := cmd.Flags().GetBool("unset")
+		doPowershell(pstoreOptions(), format, unset)
 	},
 }
 
-func doPowershell(simplePrefix, tagPrefix, pathPrefix string, verbose bool) {
-	pstore.Doit(simplePrefix, tagPrefix, pathPrefix, verbose, func(key, val string) {
-		escaped := strings.Replace(val, "\"", "\\\"", -1)
-		fmt.Printf("${Env:%s}=\"%s\"\n", key, escaped)
-	})
+func doPowershell(opts pstore.Options, format string, unset bool) {
+	remove := pstore.PowershellUnset
+
+	switch format {
+	case pstore.FormatPowershell:
+	case pstore.FormatPs1:
+		if unset {
+			fmt.Println(pstore.Ps1Header())
+		}
+	case pstore.FormatCmd:
+		remove = pstore.CmdUnset
+	default:
+		pstore.Abort(pstore.UsageError, fmt.Sprintf("unknown format '%s', expected powershell, ps1 or cmd", format))
+	}
+
+	if unset {
+		for _, name := range pstore.EnvNames(opts, warnUnset) {
+			statement, _ := remove(name)
+			fmt.Println(statement)
+		}
+		return
+	}
+
+	formatter, _ := pstore.LookupFormatter(format)
+	writeFormatted(opts, formatter)
 }
 
 func init() {
 	RootCmd.AddCommand(powershellCmd)
+	powershellCmd.Flags().Bool("unset", false, "Print statements removing the env vars instead of setting them")
+	powershellCmd.Flags().String("format", pstore.FormatPowershell, "powershell, ps1 or cmd")
 }
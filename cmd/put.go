@@ -0,0 +1,131 @@
+// Copyright © 2017 Aidan Steele <aidan.steele@glassechidna.com.au>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/fatih/color"
+	"github.com/glassechidna/pstore/pkg/pstore"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var putCmd = &cobra.Command{
+	Use:   "put <name>",
+	Short: "Writes a parameter",
+	Long: `
+Creates a parameter, or updates one if --overwrite is passed. The value is taken
+from --value, from --value-file (- for stdin) or from stdin. When stdin is a
+terminal you are prompted for it without echo. A single trailing newline is
+stripped from values read from files or stdin.
+
+example:
+	pstore put /app/prod/DB_PASSWORD --env-name DB_PASSWORD --tag env=prod`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.Usage()
+			os.Exit(pstore.UsageError)
+		}
+
+		flags := cmd.Flags()
+		req := pstore.PutRequest{Name: args[0], Tags: map[string]string{}}
+		req.Type, _ = flags.GetString("type")
+		req.KeyID, _ = flags.GetString("key-id")
+		req.Description, _ = flags.GetString("description")
+		req.AllowedPattern, _ = flags.GetString("allowed-pattern")
+		req.Tier, _ = flags.GetString("tier")
+		req.Overwrite, _ = flags.GetBool("overwrite")
+
+		tags, _ := flags.GetStringArray("tag")
+		for _, tag := range tags {
+			pair := strings.SplitN(tag, "=", 2)
+			if len(pair) != 2 {
+				pstore.Abort(pstore.UsageError, fmt.Sprintf("tag '%s' should be in key=value form", tag))
+			}
+			req.Tags[pair[0]] = pair[1]
+		}
+
+		if envName, _ := flags.GetString("env-name"); envName != "" {
+			req.Tags[pstore.EnvNameTag] = envName
+		}
+
+		req.Value = readValue(cmd, req.Name)
+		put(req)
+	},
+}
+
+// readValue gets the value to write from wherever the flags say it's coming from.
+func readValue(cmd *cobra.Command, name string) string {
+	if cmd.Flags().Changed("value") {
+		value, _ := cmd.Flags().GetString("value")
+		return value
+	}
+
+	path, _ := cmd.Flags().GetString("value-file")
+	if path == "" && term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintf(os.Stderr, "Value for %s: ", name)
+		bytes, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			pstore.Abort(pstore.UsageError, err)
+		}
+		return string(bytes)
+	}
+
+	var bytes []byte
+	var err error
+	if path == "" || path == "-" {
+		bytes, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		bytes, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		pstore.Abort(pstore.UsageError, err)
+	}
+
+	value := strings.TrimSuffix(string(bytes), "\n")
+	return strings.TrimSuffix(value, "\r")
+}
+
+func put(req pstore.PutRequest) {
+	version, err := pstore.PutParameter(pstore.NewSession(), req)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == ssm.ErrCodeParameterAlreadyExists {
+		pstore.Abort(pstore.PstoreError, fmt.Sprintf("%s already exists, pass --overwrite to update it", req.Name))
+	} else if err != nil {
+		pstore.Abort(pstore.PstoreError, err)
+	}
+
+	color.New(color.FgGreen).Fprintf(os.Stderr, "✔ Wrote %s (version %d)\n", req.Name, version)
+}
+
+func init() {
+	RootCmd.AddCommand(putCmd)
+	putCmd.Flags().String("value", "", "Value to write. Prefer --value-file or stdin for secrets, flags end up in shell history")
+	putCmd.Flags().String("value-file", "", "Read the value from this file, - for stdin")
+	putCmd.Flags().String("type", ssm.ParameterTypeSecureString, "String, StringList or SecureString")
+	putCmd.Flags().String("key-id", "", "KMS key to encrypt a SecureString with (defaults to alias/aws/ssm)")
+	putCmd.Flags().Bool("overwrite", false, "Update the parameter if it already exists")
+	putCmd.Flags().String("description", "", "Description of the parameter")
+	putCmd.Flags().String("allowed-pattern", "", "Regex the value must match")
+	putCmd.Flags().String("tier", "", "Standard, Advanced or Intelligent-Tiering")
+	putCmd.Flags().StringArray("tag", nil, "Tag in key=value form, may be repeated")
+	putCmd.Flags().String("env-name", "", "Set the "+pstore.EnvNameTag+" tag used by PSTORETAG_ references")
+}
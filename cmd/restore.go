@@ -0,0 +1,98 @@
+// Copyright © 2017 Aidan Steele <aidan.steele@glassechidna.com.au>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/glassechidna/pstore/pkg/pstore"
+	"github.com/spf13/cobra"
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <backup file>",
+	Short: "Puts back parameters from a backup written by rm",
+	Long: `
+Decrypts a backup written by pstore rm and recreates every parameter in it with
+its original type, KMS key, tier, description and tags. Parameters that exist
+again since are overwritten. The changes are listed first and --dry-run stops
+there.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.Usage()
+			os.Exit(pstore.UsageError)
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+		restore(args[0], dryRun, yes)
+	},
+}
+
+func restore(file string, dryRun, yes bool) {
+	sess := pstore.NewSession()
+
+	backup, err := pstore.ReadBackup(sess, file)
+	if err != nil {
+		pstore.Abort(pstore.PstoreError, err)
+	}
+
+	names := []string{}
+	for _, b := range backup {
+		names = append(names, b.Name)
+	}
+
+	current, err := pstore.GetParametersByName(sess, names, true)
+	if err != nil {
+		pstore.Abort(pstore.PstoreError, err)
+	}
+
+	metadata, err := pstore.DescribeParameterNames(sess, names)
+	if err != nil {
+		pstore.Abort(pstore.PstoreError, err)
+	}
+
+	changes := []pstore.Change{}
+	for _, b := range backup {
+		var tags map[string]string
+		if current[b.Name] != nil && len(b.Tags) > 0 {
+			tags, err = pstore.ListTags(sess, b.Name)
+			if err != nil {
+				pstore.Abort(pstore.PstoreError, err)
+			}
+		}
+
+		changes = append(changes, pstore.PlanChange(b.PutRequest(), current[b.Name], metadata[b.Name], tags))
+	}
+
+	if !printChanges(changes, pstoreOptions().Verbose) {
+		fmt.Fprintln(os.Stderr, "Nothing to do")
+		return
+	}
+
+	if dryRun {
+		return
+	}
+
+	confirm(yes, "y", "Apply these changes? [y/N] ")
+	applyChanges(sess, changes, 1, 3)
+}
+
+func init() {
+	RootCmd.AddCommand(restoreCmd)
+	restoreCmd.Flags().Bool("dry-run", false, "Only show what would change")
+	restoreCmd.Flags().Bool("yes", false, "Don't ask for confirmation")
+}
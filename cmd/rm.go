@@ -0,0 +1,152 @@
+// Copyright © 2017 Aidan Steele <aidan.steele@glassechidna.com.au>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/fatih/color"
+	"github.com/glassechidna/pstore/pkg/pstore"
+	"github.com/spf13/cobra"
+)
+
+var rmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Deletes a parameter, or every parameter under a path with --recursive",
+	Long: `
+Deletes a parameter, or with --recursive every parameter under a path. What
+will be deleted is listed first and you must type the name or path back to
+confirm, unless --yes is passed.
+
+Before deleting anything, the values and metadata are written to a backup
+file encrypted with the KMS key given by --backup-key. It can be put back with
+pstore restore. Pass --no-backup to skip this.
+
+example:
+	pstore rm /app/old-service --recursive --backup-key alias/backups`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.Usage()
+			os.Exit(pstore.UsageError)
+		}
+
+		flags := cmd.Flags()
+		opts := rmOptions{}
+		opts.recursive, _ = flags.GetBool("recursive")
+		opts.yes, _ = flags.GetBool("yes")
+		opts.noBackup, _ = flags.GetBool("no-backup")
+		opts.backupKey, _ = flags.GetString("backup-key")
+		opts.backupFile, _ = flags.GetString("backup-file")
+
+		if !opts.noBackup && opts.backupKey == "" {
+			pstore.Abort(pstore.UsageError, "pass --backup-key to back up deleted parameters, or --no-backup")
+		}
+
+		if opts.backupFile == "" {
+			opts.backupFile = fmt.Sprintf("pstore-backup-%s.json", time.Now().UTC().Format("20060102T150405Z"))
+		}
+
+		rm(args[0], opts)
+	},
+}
+
+type rmOptions struct {
+	recursive  bool
+	yes        bool
+	noBackup   bool
+	backupKey  string
+	backupFile string
+}
+
+func rm(name string, opts rmOptions) {
+	sess := pstore.NewSession()
+	params := []*ssm.Parameter{}
+
+	if opts.recursive {
+		var err error
+		params, err = pstore.GetParameterTree(sess, name, true, !opts.noBackup)
+		if err != nil {
+			pstore.Abort(pstore.PstoreError, err)
+		}
+	} else {
+		found, err := pstore.GetParametersByName(sess, []string{name}, !opts.noBackup)
+		if err != nil {
+			pstore.Abort(pstore.PstoreError, err)
+		}
+		if param, ok := found[name]; ok {
+			params = append(params, param)
+		}
+	}
+
+	if len(params) == 0 {
+		fmt.Fprintln(os.Stderr, "Nothing to delete")
+		return
+	}
+
+	names := []string{}
+	for _, param := range params {
+		names = append(names, *param.Name)
+		color.New(color.FgRed).Fprintf(os.Stderr, "- %s\n", *param.Name)
+	}
+
+	confirm(opts.yes, name, "Type %s to delete %d parameters: ", name, len(names))
+
+	if !opts.noBackup {
+		snapshot, err := pstore.SnapshotParameters(sess, params)
+		if err == nil {
+			err = pstore.WriteBackup(sess, opts.backupKey, opts.backupFile, snapshot)
+		}
+		if err != nil {
+			pstore.Abort(pstore.PstoreError, fmt.Sprintf("failed to back up parameters, nothing was deleted: %s", err))
+		}
+		fmt.Fprintf(os.Stderr, "Backed up %d parameters to %s\n", len(snapshot), opts.backupFile)
+	}
+
+	deleteParameters(sess, names)
+}
+
+// deleteParameters deletes names, reporting each failure and a summary of
+// what happened. It exits non-zero if anything failed.
+func deleteParameters(sess *session.Session, names []string) {
+	deleted, failed := 0, 0
+
+	pstore.DeleteParameters(sess, names, func(name string, err error) {
+		if err != nil {
+			color.New(color.FgRed).Fprintf(os.Stderr, "✗ Failed to delete %s: %s\n", name, err)
+			failed++
+			return
+		}
+		deleted++
+	})
+
+	fmt.Fprintf(os.Stderr, "%d deleted, %d failed\n", deleted, failed)
+
+	if failed > 0 {
+		os.Exit(pstore.PstoreError)
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(rmCmd)
+	rmCmd.Flags().BoolP("recursive", "r", false, "Delete every parameter under the path")
+	rmCmd.Flags().Bool("yes", false, "Don't ask for confirmation")
+	rmCmd.Flags().String("backup-key", "", "KMS key to encrypt the backup of deleted parameters with")
+	rmCmd.Flags().String("backup-file", "", "Where to write the backup (defaults to pstore-backup-<timestamp>.json)")
+	rmCmd.Flags().Bool("no-backup", false, "Don't back up deleted parameters")
+}
@@ -15,11 +15,15 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/glassechidna/pstore/pkg/pstore"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"golang.org/x/term"
 )
 
 var cfgFile string
@@ -30,14 +34,21 @@ var RootCmd = &cobra.Command{
 	Long: `pstore is usable out of the box. By default it looks for environment variables with a PSTORE_ prefix. For example, PSTORE_DBSTRING=MyDatabaseString asks AWS to decrypt the parameter named MyDatabaseString and stores the decrypted value in a new environment variable named DBSTRING. If there are no envvars with the PSTORE_ prefix, it's essentially a noop - so the same command can be used in local dev and in prod.
 
 	If pstore fails to decrypt any envvars it will exit instead of launching your application.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if err := pstore.ValidLogFormat(viper.GetString("log-format")); err != nil {
+			pstore.Abort(pstore.UsageError, err)
+		}
+	},
 }
 
 // Execute adds all child commands to the root command sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	// cobra only fails on bad flags and arguments, and stdout may be
+	// about to be eval'd, so the error goes to stderr
 	if err := RootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(-1)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(pstore.UsageError)
 	}
 }
 
@@ -48,6 +59,8 @@ func init() {
 	RootCmd.PersistentFlags().String("tag-prefix", "PSTORETAG_", "")
 	RootCmd.PersistentFlags().String("path-prefix", "PSTOREPATH_", "")
 	RootCmd.PersistentFlags().Bool("verbose", false, "")
+	RootCmd.PersistentFlags().String("log-format", pstore.LogFormatText, "Format of diagnostics written to stderr: text or json")
+	RootCmd.PersistentFlags().String("report", "", "Write a JSON summary of resolved parameters (never their values) to this file")
 
 	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.pstore.yaml)")
 
@@ -66,6 +79,37 @@ func initConfig() {
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+	}
+}
+
+// pstoreOptions collects the persistent flags shared by every subcommand.
+func pstoreOptions() pstore.Options {
+	return pstore.Options{
+		SimplePrefix: viper.GetString("prefix"),
+		TagPrefix:    viper.GetString("tag-prefix"),
+		PathPrefix:   viper.GetString("path-prefix"),
+		Verbose:      viper.GetBool("verbose"),
+		LogFormat:    viper.GetString("log-format"),
+		ReportFile:   viper.GetString("report"),
+	}
+}
+
+// confirm asks the user to type expected before going any further, aborting
+// if they type anything else. yes skips the question for scripted use.
+func confirm(yes bool, expected, format string, a ...interface{}) {
+	if yes {
+		return
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		pstore.Abort(pstore.UsageError, "refusing to continue without confirmation, pass --yes")
+	}
+
+	fmt.Fprintf(os.Stderr, format, a...)
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+
+	if strings.TrimSpace(line) != expected {
+		pstore.Abort(pstore.UsageError, "Aborted")
 	}
 }
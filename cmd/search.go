@@ -0,0 +1,249 @@
+// Copyright © 2017 Aidan Steele <aidan.steele@glassechidna.com.au>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/fatih/color"
+	"github.com/glassechidna/pstore/pkg/pstore"
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <term>",
+	Short: "Finds parameters anywhere in the account by part of their name",
+	Long: `
+Lists every parameter whose name contains the term, or begins with it when
+--begins-with is passed, along with its metadata. The search can be narrowed
+with --tag key=value (or just key), --type, --key-id and --tier, and run
+across several regions at once with --regions.
+
+Values aren't fetched unless --decrypt is passed.
+
+example:
+	pstore search DATABASE_URL --regions us-east-1,ap-southeast-2`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.Usage()
+			os.Exit(pstore.UsageError)
+		}
+
+		flags := cmd.Flags()
+		opts := searchOptions{}
+		opts.beginsWith, _ = flags.GetBool("begins-with")
+		opts.tags, _ = flags.GetStringArray("tag")
+		opts.types, _ = flags.GetStringArray("type")
+		opts.keyID, _ = flags.GetString("key-id")
+		opts.tier, _ = flags.GetString("tier")
+		opts.regions, _ = flags.GetStringSlice("regions")
+		opts.decrypt, _ = flags.GetBool("decrypt")
+		opts.json, _ = flags.GetBool("json")
+
+		search(args[0], opts)
+	},
+}
+
+type searchOptions struct {
+	beginsWith bool
+	tags       []string
+	types      []string
+	keyID      string
+	tier       string
+	regions    []string
+	decrypt    bool
+	json       bool
+}
+
+// searchResult is a parameter found by search.
+type searchResult struct {
+	Region           string    `json:"region"`
+	Name             string    `json:"name"`
+	Type             string    `json:"type"`
+	Version          int64     `json:"version"`
+	LastModifiedDate time.Time `json:"last_modified_date"`
+	LastModifiedUser string    `json:"last_modified_user,omitempty"`
+	Tier             string    `json:"tier,omitempty"`
+	DataType         string    `json:"data_type,omitempty"`
+	KeyID            string    `json:"key_id,omitempty"`
+	Value            *string   `json:"value,omitempty"`
+}
+
+func searchFilters(term string, opts searchOptions) []*ssm.ParameterStringFilter {
+	filters := []*ssm.ParameterStringFilter{pstore.NameFilter(term, opts.beginsWith)}
+
+	if len(opts.types) > 0 {
+		filter, err := pstore.TypeFilter(opts.types)
+		if err != nil {
+			pstore.Abort(pstore.UsageError, err)
+		}
+		filters = append(filters, filter)
+	}
+
+	for _, tag := range opts.tags {
+		filter, err := pstore.TagFilter(tag)
+		if err != nil {
+			pstore.Abort(pstore.UsageError, err)
+		}
+		filters = append(filters, filter)
+	}
+
+	if opts.keyID != "" {
+		filters = append(filters, pstore.KeyIDFilter(opts.keyID))
+	}
+
+	if opts.tier != "" {
+		filter, err := pstore.TierFilter(opts.tier)
+		if err != nil {
+			pstore.Abort(pstore.UsageError, err)
+		}
+		filters = append(filters, filter)
+	}
+
+	return filters
+}
+
+func search(term string, opts searchOptions) {
+	filters := searchFilters(term, opts)
+
+	regions := opts.regions
+	if len(regions) == 0 {
+		regions = []string{""}
+	}
+
+	results := make([][]searchResult, len(regions))
+	errs := make([]error, len(regions))
+
+	wg := sync.WaitGroup{}
+	for idx, region := range regions {
+		wg.Add(1)
+		go func(idx int, region string) {
+			defer wg.Done()
+			results[idx], errs[idx] = searchRegion(region, filters, opts.decrypt)
+		}(idx, region)
+	}
+	wg.Wait()
+
+	all := []searchResult{}
+	failed := false
+	for idx, err := range errs {
+		if err != nil {
+			color.New(color.FgRed).Fprintf(os.Stderr, "✗ %s: %s\n", regions[idx], err)
+			failed = true
+			continue
+		}
+		all = append(all, results[idx]...)
+	}
+
+	if opts.json {
+		bytes, _ := json.MarshalIndent(all, "", "  ")
+		fmt.Println(string(bytes))
+	} else {
+		printSearchResults(all, len(regions) > 1, opts.decrypt)
+	}
+
+	if failed {
+		os.Exit(pstore.PstoreError)
+	}
+}
+
+func searchRegion(region string, filters []*ssm.ParameterStringFilter, decrypt bool) ([]searchResult, error) {
+	sess := pstore.NewSessionFor(region, "")
+	region = aws.StringValue(sess.Config.Region)
+
+	found, err := pstore.SearchParameters(sess, filters...)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]*ssm.Parameter{}
+	if decrypt {
+		names := []string{}
+		for _, meta := range found {
+			names = append(names, *meta.Name)
+		}
+
+		values, err = pstore.GetParametersByName(sess, names, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	results := []searchResult{}
+	for _, meta := range found {
+		result := searchResult{
+			Region:           region,
+			Name:             *meta.Name,
+			Type:             aws.StringValue(meta.Type),
+			Version:          aws.Int64Value(meta.Version),
+			LastModifiedDate: aws.TimeValue(meta.LastModifiedDate),
+			LastModifiedUser: aws.StringValue(meta.LastModifiedUser),
+			Tier:             aws.StringValue(meta.Tier),
+			DataType:         aws.StringValue(meta.DataType),
+			KeyID:            aws.StringValue(meta.KeyId),
+		}
+		if param, ok := values[*meta.Name]; ok {
+			result.Value = param.Value
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func printSearchResults(results []searchResult, showRegion, showValue bool) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+	header := "NAME\tTYPE\tVERSION\tLAST MODIFIED\tMODIFIED BY\tTIER\tKEY"
+	if showRegion {
+		header = "REGION\t" + header
+	}
+	if showValue {
+		header += "\tVALUE"
+	}
+	fmt.Fprintln(w, header)
+
+	for _, r := range results {
+		line := fmt.Sprintf("%s\t%s\t%d\t%s\t%s\t%s\t%s", r.Name, r.Type, r.Version, r.LastModifiedDate.Format("2006-01-02 15:04:05"), r.LastModifiedUser, r.Tier, r.KeyID)
+		if showRegion {
+			line = r.Region + "\t" + line
+		}
+		if showValue {
+			line += "\t" + aws.StringValue(r.Value)
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	w.Flush()
+}
+
+func init() {
+	RootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().Bool("begins-with", false, "Match names beginning with the term rather than containing it")
+	searchCmd.Flags().StringArray("tag", []string{}, "Only find parameters tagged key=value, or with the tag key")
+	searchCmd.Flags().StringArray("type", []string{}, "Only find parameters of this type (String, StringList or SecureString)")
+	searchCmd.Flags().String("key-id", "", "Only find SecureStrings encrypted with this KMS key")
+	searchCmd.Flags().String("tier", "", "Only find parameters in this tier (Standard, Advanced or Intelligent-Tiering)")
+	searchCmd.Flags().StringSlice("regions", nil, "Comma-separated regions to search, instead of the default region")
+	searchCmd.Flags().Bool("decrypt", false, "Fetch and decrypt values too")
+	searchCmd.Flags().BoolP("json", "j", false, "Emit JSON instead of a table")
+}
@@ -16,39 +16,76 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
-	"strings"
-
+	"github.com/fatih/color"
 	"github.com/glassechidna/pstore/pkg/pstore"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 var shellCmd = &cobra.Command{
 	Use:   "shell",
 	Short: "shell will seed your session with environment with the ssm parameters, and will not execute a child process",
-	Long: `Example:
+	Long: `
+Prints a statement setting each parameter as an environment variable, quoted
+so that any value, including multiline ones, is taken literally. The syntax is
+that of --shell (sh, bash, zsh, fish, tcsh or nu), which defaults to $SHELL.
+
+Example:
 	#!/bin/bash
 	# do some stuff ...
-	eval $(PSTORE_DBSTRING=MyDatabaseString pstore shell)
-	echo $DBSTRING # will echo out your secret string!`,
+	eval "$(PSTORE_DBSTRING=MyDatabaseString pstore shell)"
+	echo $DBSTRING # will echo out your secret string!
+
+In fish, pipe the output to source instead:
+	PSTORE_DBSTRING=MyDatabaseString pstore shell | source
+
+--unset prints statements removing the same env vars instead, without
+decrypting anything, to clear secrets before later steps run:
+	eval "$(PSTORE_DBSTRING=MyDatabaseString pstore shell --unset)"`,
 	Run: func(cmd *cobra.Command, args []string) {
-		simplePrefix := viper.GetString("prefix")
-		tagPrefix := viper.GetString("tag-prefix")
-		pathPrefix := viper.GetString("path-prefix")
-		verbose := viper.GetBool("verbose")
+		dialect, _ := cmd.Flags().GetString("shell")
+		if dialect == "" {
+			dialect = pstore.DetectShell()
+		}
+
+		if err := pstore.ValidShell(dialect); err != nil {
+			pstore.Abort(pstore.UsageError, err)
+		}
+
+		if unset, _ := cmd.Flags().GetBool("unset"); unset {
+			doShellUnset(pstoreOptions(), dialect)
+			return
+		}
 
-		doShell(simplePrefix, tagPrefix, pathPrefix, verbose)
+		doShell(pstoreOptions(), dialect)
 	},
 }
 
-func doShell(simplePrefix, tagPrefix, pathPrefix string, verbose bool) {
-	pstore.Doit(simplePrefix, tagPrefix, pathPrefix, verbose, func(key, val string) {
-		escaped := strings.Replace(val, "'", "\\'", -1)
-		fmt.Printf("export %s='%s'\n", key, escaped)
-	})
+func doShell(opts pstore.Options, dialect string) {
+	formatter, err := pstore.LookupFormatter(dialect)
+	if err != nil {
+		pstore.Abort(pstore.UsageError, err)
+	}
+
+	writeFormatted(opts, formatter)
+}
+
+func doShellUnset(opts pstore.Options, dialect string) {
+	for _, name := range pstore.EnvNames(opts, warnUnset) {
+		statement, _ := pstore.ShellUnset(dialect, name)
+		fmt.Println(statement)
+	}
+}
+
+// warnUnset reports env var names --unset couldn't work out. The names it
+// could are still printed, so as many secrets as possible get cleared.
+func warnUnset(err error) {
+	color.New(color.FgYellow).Fprintf(os.Stderr, "WARNING: %s\n", err)
 }
 
 func init() {
 	RootCmd.AddCommand(shellCmd)
+	shellCmd.Flags().Bool("unset", false, "Print statements removing the env vars instead of setting them")
+	shellCmd.Flags().String("shell", "", "Shell to write statements for: sh, bash, zsh, fish, tcsh or nu (default from $SHELL)")
 }
@@ -22,64 +22,366 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ssm"
 	"github.com/fatih/color"
+	"github.com/glassechidna/pstore/pkg/pstore"
 	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"regexp"
 	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 )
 
 var showCmd = &cobra.Command{
-	Use:   "show <path>",
+	Use:   "show <path>...",
 	Short: "Prints all parameters under a prefix",
 	Long: `
-Prints all parameters under a given prefix. Defaults to human-friendly format,
-pass -j if you'd rather JSON output.
+Prints all parameters under one or more prefixes. Defaults to human-friendly
+format, pass -j if you'd rather JSON output.
+
+--no-recursive only lists parameters directly under each path, and
+--max-depth=N those at most N levels below it. --match filters names by a
+regular expression, --type by parameter type and --tag by key=value (or just
+key). Repeated --type flags match any of the types, repeated --tag flags must
+all match. --no-decrypt lists SecureStrings without needing kms:Decrypt.
+
+SecureString values are masked unless --reveal-all is passed. --reveal <glob>
+only reveals parameters whose name (full or relative to the path) matches the
+glob.
+
+--long adds each parameter's type, version, last modified date and user, tier,
+data type, KMS key and policies to either format. --sort orders by any of
+name, type, version, modified, user, tier, data-type or key.
+
+--tree draws the hierarchy with a count of parameters in each subtree, and
+-j --nested emits nested JSON objects mirroring the path segments.
 `,
 	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			cmd.Usage()
+			os.Exit(pstore.UsageError)
+		}
+
 		jsonFormat, _ := cmd.PersistentFlags().GetBool("json")
-		path := args[0]
-		show(path, jsonFormat)
+		reveal, _ := cmd.PersistentFlags().GetString("reveal")
+		if all, _ := cmd.PersistentFlags().GetBool("reveal-all"); all {
+			reveal = revealAll
+		}
+		mask, _ := cmd.PersistentFlags().GetString("mask")
+		long, _ := cmd.PersistentFlags().GetBool("long")
+		sortBy, _ := cmd.PersistentFlags().GetString("sort")
+		tree, _ := cmd.PersistentFlags().GetBool("tree")
+		nested, _ := cmd.PersistentFlags().GetBool("nested")
+		noRecursive, _ := cmd.PersistentFlags().GetBool("no-recursive")
+		maxDepth, _ := cmd.PersistentFlags().GetInt("max-depth")
+		match, _ := cmd.PersistentFlags().GetString("match")
+		types, _ := cmd.PersistentFlags().GetStringArray("type")
+		tags, _ := cmd.PersistentFlags().GetStringArray("tag")
+		noDecrypt, _ := cmd.PersistentFlags().GetBool("no-decrypt")
+		show(args, showOptions{
+			jsonFormat: jsonFormat,
+			reveal:     reveal,
+			mask:       mask,
+			long:       long,
+			sortBy:     sortBy,
+			tree:       tree,
+			nested:     nested,
+			recursive:  !noRecursive,
+			maxDepth:   maxDepth,
+			match:      match,
+			types:      types,
+			tags:       tags,
+			decrypt:    !noDecrypt,
+		})
 	},
 }
 
-func getAllParameters(sess *session.Session, path string) []*ssm.Parameter {
-	api := ssm.New(sess)
+type showOptions struct {
+	jsonFormat bool
+	reveal     string
+	mask       string
+	long       bool
+	sortBy     string
+	tree       bool
+	nested     bool
+	recursive  bool
+	maxDepth   int
+	match      string
+	types      []string
+	tags       []string
+	decrypt    bool
+}
+
+// showFilter selects which parameters under each path are shown.
+type showFilter struct {
+	recursive   bool
+	maxDepth    int
+	match       *regexp.Regexp
+	pathFilters []*ssm.ParameterStringFilter
+	tagFilters  []*ssm.ParameterStringFilter
+}
+
+func newShowFilter(opts showOptions) showFilter {
+	if opts.maxDepth < 0 {
+		pstore.Abort(pstore.UsageError, "--max-depth can't be negative")
+	}
+
+	if !opts.recursive && opts.maxDepth > 1 {
+		pstore.Abort(pstore.UsageError, "--no-recursive can't be combined with a --max-depth greater than 1")
+	}
+
+	filter := showFilter{
+		recursive: opts.recursive && opts.maxDepth != 1,
+		maxDepth:  opts.maxDepth,
+	}
+
+	if opts.match != "" {
+		match, err := regexp.Compile(opts.match)
+		if err != nil {
+			pstore.Abort(pstore.UsageError, fmt.Sprintf("--match isn't a valid regular expression: %s", err))
+		}
+		filter.match = match
+	}
+
+	if len(opts.types) > 0 {
+		typeFilter, err := pstore.TypeFilter(opts.types)
+		if err != nil {
+			pstore.Abort(pstore.UsageError, err)
+		}
+		filter.pathFilters = append(filter.pathFilters, typeFilter)
+	}
 
+	for _, tag := range opts.tags {
+		tagFilter, err := pstore.TagFilter(tag)
+		if err != nil {
+			pstore.Abort(pstore.UsageError, err)
+		}
+		filter.tagFilters = append(filter.tagFilters, tagFilter)
+	}
+
+	return filter
+}
+
+func (f showFilter) depthAllowed(path, name string) bool {
+	if f.maxDepth == 0 {
+		return true
+	}
+	return strings.Count(pstore.RelativeName(path, name), "/") < f.maxDepth
+}
+
+// getAllParameters returns the parameters under each of paths that pass
+// filter, sorted by name and without duplicates.
+func getAllParameters(sess *session.Session, paths []string, filter showFilter, decrypt bool) ([]*ssm.Parameter, error) {
+	seen := map[string]bool{}
 	params := []*ssm.Parameter{}
 
-	api.GetParametersByPathPages(&ssm.GetParametersByPathInput{
-		Path:           &path,
-		Recursive:      aws.Bool(true),
-		WithDecryption: aws.Bool(true),
-	}, func(page *ssm.GetParametersByPathOutput, lastPage bool) bool {
-		params = append(params, page.Parameters...)
-		return !lastPage
+	for _, path := range paths {
+		tree, err := pstore.GetParameterTree(sess, path, filter.recursive, decrypt, filter.pathFilters...)
+		if err != nil {
+			return nil, err
+		}
+
+		var tagged map[string]*ssm.ParameterMetadata
+		if len(filter.tagFilters) > 0 {
+			tagged, err = pstore.DescribeParameterTree(sess, path, filter.recursive, filter.tagFilters...)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, param := range tree {
+			name := *param.Name
+			switch {
+			case seen[name]:
+			case !filter.depthAllowed(path, name):
+			case filter.match != nil && !filter.match.MatchString(name):
+			case tagged != nil && tagged[name] == nil:
+			default:
+				seen[name] = true
+				params = append(params, param)
+			}
+		}
+	}
+
+	sort.Slice(params, func(i, j int) bool {
+		return *params[i].Name < *params[j].Name
 	})
 
-	sort.Sort(byName(params))
-	return params
+	return params, nil
 }
 
-func show(path string, jsonFormat bool) {
+// commonPath is the deepest path that all of paths are beneath, used to
+// shorten names when printing.
+func commonPath(paths []string) string {
+	if len(paths) == 1 {
+		return paths[0]
+	}
+
+	common := strings.Split(strings.Trim(paths[0], "/"), "/")
+	for _, path := range paths[1:] {
+		segments := strings.Split(strings.Trim(path, "/"), "/")
+		idx := 0
+		for idx < len(common) && idx < len(segments) && common[idx] == segments[idx] {
+			idx++
+		}
+		common = common[:idx]
+	}
+
+	return "/" + strings.Join(common, "/")
+}
+
+func show(paths []string, opts showOptions) {
+	if opts.mask != maskFull && opts.mask != maskPartial {
+		pstore.Abort(pstore.UsageError, fmt.Sprintf("--mask should be %s or %s", maskFull, maskPartial))
+	}
+
+	if _, ok := sortKeys[opts.sortBy]; !ok {
+		pstore.Abort(pstore.UsageError, fmt.Sprintf("can't sort by '%s'", opts.sortBy))
+	}
+
+	if opts.nested && !opts.jsonFormat {
+		pstore.Abort(pstore.UsageError, "--nested only applies to JSON output, pass -j too")
+	}
+
+	filter := newShowFilter(opts)
+	path := commonPath(paths)
+
 	sess := session.Must(session.NewSession())
-	params := getAllParameters(sess, path)
+	params, err := getAllParameters(sess, paths, filter, opts.decrypt)
+	if err != nil {
+		pstore.Abort(pstore.PstoreError, err)
+	}
+	maskSecrets(params, path, opts.reveal, opts.mask == maskPartial)
+
+	metadata := map[string]*ssm.ParameterMetadata{}
+	if opts.long || opts.sortBy != "name" {
+		for _, p := range paths {
+			tree, err := pstore.DescribeParameterTree(sess, p, filter.recursive)
+			if err != nil {
+				pstore.Abort(pstore.PstoreError, err)
+			}
+			for name, meta := range tree {
+				metadata[name] = meta
+			}
+		}
+	}
+
+	details := []paramDetails{}
+	for _, param := range params {
+		details = append(details, newParamDetails(param, metadata[*param.Name]))
+	}
+
+	key := sortKeys[opts.sortBy]
+	sort.SliceStable(details, func(i, j int) bool {
+		return key(details[i]) < key(details[j])
+	})
+
+	switch {
+	case opts.nested:
+		printJsonNested(details, path, opts.long)
+	case opts.jsonFormat && opts.long:
+		printJsonLong(details)
+	case opts.jsonFormat:
+		printJson(details, path)
+	case opts.tree:
+		printTree(details, path)
+	case opts.long:
+		printLong(details)
+	default:
+		printFriendly(details, path)
+	}
+}
+
+// paramDetails is a parameter along with the metadata shown by --long.
+type paramDetails struct {
+	Name             string    `json:"-"`
+	Value            string    `json:"value"`
+	Type             string    `json:"type"`
+	Version          int64     `json:"version"`
+	LastModifiedDate time.Time `json:"last_modified_date"`
+	LastModifiedUser string    `json:"last_modified_user,omitempty"`
+	Tier             string    `json:"tier,omitempty"`
+	DataType         string    `json:"data_type,omitempty"`
+	KeyID            string    `json:"key_id,omitempty"`
+	Policies         []string  `json:"policies,omitempty"`
+}
+
+func newParamDetails(param *ssm.Parameter, meta *ssm.ParameterMetadata) paramDetails {
+	details := paramDetails{
+		Name:             *param.Name,
+		Value:            *param.Value,
+		Type:             *param.Type,
+		Version:          *param.Version,
+		LastModifiedDate: aws.TimeValue(param.LastModifiedDate),
+		DataType:         aws.StringValue(param.DataType),
+	}
+
+	if meta != nil {
+		details.LastModifiedUser = aws.StringValue(meta.LastModifiedUser)
+		details.Tier = aws.StringValue(meta.Tier)
+		details.KeyID = aws.StringValue(meta.KeyId)
+		for _, policy := range meta.Policies {
+			details.Policies = append(details.Policies, fmt.Sprintf("%s (%s)", aws.StringValue(policy.PolicyType), aws.StringValue(policy.PolicyStatus)))
+		}
+	}
 
-	if jsonFormat {
-		printJson(params, path)
-	} else {
-		printFriendly(params, path)
+	return details
+}
+
+// sortKeys maps --sort columns to values that order parameters by them
+var sortKeys = map[string]func(p paramDetails) string{
+	"name":      func(p paramDetails) string { return p.Name },
+	"type":      func(p paramDetails) string { return p.Type },
+	"version":   func(p paramDetails) string { return fmt.Sprintf("%020d", p.Version) },
+	"modified":  func(p paramDetails) string { return fmt.Sprintf("%020d", p.LastModifiedDate.UnixNano()) },
+	"user":      func(p paramDetails) string { return p.LastModifiedUser },
+	"tier":      func(p paramDetails) string { return p.Tier },
+	"data-type": func(p paramDetails) string { return p.DataType },
+	"key":       func(p paramDetails) string { return p.KeyID },
+}
+
+const (
+	maskFull    = "full"
+	maskPartial = "partial"
+	revealAll   = "*"
+)
+
+// maskSecrets masks the values of SecureStrings, other than those whose
+// full or relative name matches the reveal glob.
+func maskSecrets(params []*ssm.Parameter, path, reveal string, partial bool) {
+	for _, param := range params {
+		if *param.Type != ssm.ParameterTypeSecureString || revealed(reveal, path, *param.Name) {
+			continue
+		}
+		param.Value = aws.String(maskValue(*param.Value, partial))
 	}
 }
 
-func printFriendly(params []*ssm.Parameter, path string) {
+func revealed(reveal, path, name string) bool {
+	if reveal == revealAll {
+		return true
+	}
+	if reveal == "" {
+		return false
+	}
+
+	full, _ := filepath.Match(reveal, name)
+	relative, _ := filepath.Match(reveal, pstore.RelativeName(path, name))
+	return full || relative
+}
+
+func printFriendly(params []paramDetails, path string) {
 	longest := longestName(params)
 	padding := longest - len(faint("%s", path))
 
 	secret := color.New(color.FgRed, color.Bold).SprintfFunc()
 
 	for _, param := range params {
-		prefix, rest := (*param.Name)[:len(path)], (*param.Name)[len(path):]
-		value := *param.Value
+		prefix, rest := param.Name[:len(path)], param.Name[len(path):]
+		value := param.Value
 
-		if *param.Type == ssm.ParameterTypeSecureString {
+		if param.Type == ssm.ParameterTypeSecureString {
 			value = secret("%s", value)
 		}
 
@@ -87,34 +389,46 @@ func printFriendly(params []*ssm.Parameter, path string) {
 	}
 }
 
-func printJson(params []*ssm.Parameter, path string) {
+func printJson(params []paramDetails, path string) {
 	dict := map[string]string{}
 
 	for _, param := range params {
-		dict[*param.Name] = *param.Value
+		dict[param.Name] = param.Value
 	}
 
 	bytes, _ := json.MarshalIndent(dict, "", "  ")
 	fmt.Println(string(bytes))
 }
 
-type byName []*ssm.Parameter
+func printJsonLong(params []paramDetails) {
+	dict := map[string]paramDetails{}
 
-func (s byName) Len() int {
-	return len(s)
-}
-func (s byName) Swap(i, j int) {
-	s[i], s[j] = s[j], s[i]
+	for _, param := range params {
+		dict[param.Name] = param
+	}
+
+	bytes, _ := json.MarshalIndent(dict, "", "  ")
+	fmt.Println(string(bytes))
 }
-func (s byName) Less(i, j int) bool {
-	return *s[i].Name < *s[j].Name
+
+func printLong(params []paramDetails) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVALUE\tTYPE\tVERSION\tLAST MODIFIED\tMODIFIED BY\tTIER\tDATA TYPE\tKEY\tPOLICIES")
+
+	for _, p := range params {
+		modified := p.LastModifiedDate.Format("2006-01-02 15:04:05")
+		policies := strings.Join(p.Policies, ", ")
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\n", p.Name, p.Value, p.Type, p.Version, modified, p.LastModifiedUser, p.Tier, p.DataType, p.KeyID, policies)
+	}
+
+	w.Flush()
 }
 
-func longestName(params []*ssm.Parameter) int {
+func longestName(params []paramDetails) int {
 	longest := 0
 
 	for _, param := range params {
-		plen := len(faint("%s", *param.Name))
+		plen := len(faint("%s", param.Name))
 		if plen > longest {
 			longest = plen
 		}
@@ -128,6 +442,19 @@ var faint func(format string, a ...interface{}) string
 func init() {
 	RootCmd.AddCommand(showCmd)
 	showCmd.PersistentFlags().BoolP("json", "j", false, "Emit JSON instead of table")
+	showCmd.PersistentFlags().String("reveal", "", "Show the values of SecureStrings whose name matches this glob")
+	showCmd.PersistentFlags().Bool("reveal-all", false, "Show all SecureString values")
+	showCmd.PersistentFlags().BoolP("long", "l", false, "Include type, version, modification and KMS key details")
+	showCmd.PersistentFlags().String("sort", "name", "Column to sort by")
+	showCmd.PersistentFlags().Bool("tree", false, "Draw the parameter hierarchy as a tree")
+	showCmd.PersistentFlags().Bool("nested", false, "With -j, emit nested objects mirroring the path segments")
+	showCmd.PersistentFlags().Bool("no-recursive", false, "Only list parameters directly under each path")
+	showCmd.PersistentFlags().Int("max-depth", 0, "Only list parameters at most this many levels below each path (0 for no limit)")
+	showCmd.PersistentFlags().String("match", "", "Only list parameters whose name matches this regular expression")
+	showCmd.PersistentFlags().StringArray("type", []string{}, "Only list parameters of this type (String, StringList or SecureString)")
+	showCmd.PersistentFlags().StringArray("tag", []string{}, "Only list parameters tagged key=value, or with the tag key")
+	showCmd.PersistentFlags().Bool("no-decrypt", false, "Don't decrypt SecureStrings, for when you lack kms:Decrypt")
+	showCmd.PersistentFlags().String("mask", maskFull, "How to mask SecureStrings: full, or partial to leave the first and last characters visible")
 
 	faint = color.New(color.Faint).SprintfFunc()
 }
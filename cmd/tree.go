@@ -0,0 +1,133 @@
+// Copyright © 2017 Aidan Steele <aidan.steele@glassechidna.com.au>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/fatih/color"
+	"github.com/glassechidna/pstore/pkg/pstore"
+)
+
+// treeNode is a segment of a parameter hierarchy. A node can be both a
+// parameter and the parent of others, e.g. /app/db and /app/db/host.
+type treeNode struct {
+	param    *paramDetails
+	children map[string]*treeNode
+	count    int // parameters at or below this node
+}
+
+func buildTree(params []paramDetails, path string) *treeNode {
+	root := &treeNode{children: map[string]*treeNode{}}
+
+	for idx := range params {
+		node := root
+		node.count++
+
+		for _, segment := range strings.Split(pstore.RelativeName(path, params[idx].Name), "/") {
+			child, ok := node.children[segment]
+			if !ok {
+				child = &treeNode{children: map[string]*treeNode{}}
+				node.children[segment] = child
+			}
+			node = child
+			node.count++
+		}
+
+		node.param = &params[idx]
+	}
+
+	return root
+}
+
+func (n *treeNode) sortedChildren() []string {
+	names := []string{}
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func printTree(params []paramDetails, path string) {
+	root := buildTree(params, path)
+	fmt.Printf("%s %s\n", path, faint("(%d)", root.count))
+	printTreeChildren(root, "")
+}
+
+func printTreeChildren(node *treeNode, indent string) {
+	secret := color.New(color.FgRed, color.Bold).SprintfFunc()
+	names := node.sortedChildren()
+
+	for idx, name := range names {
+		child := node.children[name]
+
+		branch, nextIndent := "├── ", indent+"│   "
+		if idx == len(names)-1 {
+			branch, nextIndent = "└── ", indent+"    "
+		}
+
+		line := name
+		if child.param != nil {
+			value := child.param.Value
+			if child.param.Type == ssm.ParameterTypeSecureString {
+				value = secret("%s", value)
+			}
+			line += " : " + value
+		}
+		if len(child.children) > 0 {
+			line += " " + faint("(%d)", child.count)
+		}
+
+		fmt.Println(faint("%s%s", indent, branch) + line)
+		printTreeChildren(child, nextIndent)
+	}
+}
+
+// printJsonNested prints parameters as nested objects mirroring the path
+// segments below path. A parameter that is also the parent of others is
+// stored under the empty key of its object.
+func printJsonNested(params []paramDetails, path string, long bool) {
+	bytes, _ := json.MarshalIndent(nestedValue(buildTree(params, path), long), "", "  ")
+	fmt.Println(string(bytes))
+}
+
+func nestedValue(node *treeNode, long bool) interface{} {
+	var leaf interface{}
+	if node.param != nil {
+		leaf = node.param.Value
+		if long {
+			leaf = node.param
+		}
+	}
+
+	if len(node.children) == 0 && leaf != nil {
+		return leaf
+	}
+
+	obj := map[string]interface{}{}
+	for name, child := range node.children {
+		obj[name] = nestedValue(child, long)
+	}
+	if leaf != nil {
+		obj[""] = leaf
+	}
+
+	return obj
+}
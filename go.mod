@@ -3,7 +3,7 @@ module github.com/glassechidna/pstore
 go 1.14
 
 require (
-	github.com/aws/aws-sdk-go v1.29.27
+	github.com/aws/aws-sdk-go v1.38.15
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/fatih/color v1.5.0
 	github.com/fsnotify/fsnotify v0.0.0-20170329110642-4da3e2cfbabc // indirect
@@ -11,7 +11,6 @@ require (
 	github.com/gopherjs/gopherjs v0.0.0-20181103185306-d547d1d9531e // indirect
 	github.com/hashicorp/hcl v0.0.0-20170509225359-392dba7d905e // indirect
 	github.com/inconshreveable/mousetrap v1.0.0 // indirect
-	github.com/jmespath/go-jmespath v0.3.0 // indirect
 	github.com/jtolds/gls v4.2.1+incompatible // indirect
 	github.com/kr/pretty v0.1.0 // indirect
 	github.com/magiconair/properties v0.0.0-20170321093039-51463bfca257 // indirect
@@ -29,5 +28,8 @@ require (
 	github.com/spf13/jwalterweatherman v0.0.0-20170510083831-8f07c835e5cc // indirect
 	github.com/spf13/pflag v1.0.0 // indirect
 	github.com/spf13/viper v0.0.0-20170417080815-0967fc9aceab
+	github.com/stretchr/testify v1.5.1 // indirect
+	golang.org/x/term v0.0.0-20201126162022-7de9c90e9dd1
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
+	gopkg.in/yaml.v2 v2.2.8
 )
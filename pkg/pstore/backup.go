@@ -0,0 +1,173 @@
+package pstore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+const backupFormatVersion = 1
+
+// BackupParameter is everything needed to recreate a parameter.
+type BackupParameter struct {
+	Name           string            `json:"name"`
+	Type           string            `json:"type"`
+	Value          string            `json:"value"`
+	Description    string            `json:"description,omitempty"`
+	KeyID          string            `json:"key_id,omitempty"`
+	Tier           string            `json:"tier,omitempty"`
+	AllowedPattern string            `json:"allowed_pattern,omitempty"`
+	Tags           map[string]string `json:"tags,omitempty"`
+}
+
+type backupContents struct {
+	CreatedAt  time.Time         `json:"created_at"`
+	Parameters []BackupParameter `json:"parameters"`
+}
+
+// backupFile is what's written to disk: the contents encrypted with
+// AES-256-GCM under a data key that is itself encrypted by KMS.
+type backupFile struct {
+	Version      int    `json:"version"`
+	EncryptedKey []byte `json:"encrypted_key"`
+	Nonce        []byte `json:"nonce"`
+	Ciphertext   []byte `json:"ciphertext"`
+}
+
+func (b BackupParameter) PutRequest() PutRequest {
+	return PutRequest{
+		Name:           b.Name,
+		Value:          b.Value,
+		Type:           b.Type,
+		KeyID:          b.KeyID,
+		Description:    b.Description,
+		AllowedPattern: b.AllowedPattern,
+		Tier:           b.Tier,
+		Overwrite:      true,
+		Tags:           b.Tags,
+	}
+}
+
+// SnapshotParameters gathers the values and metadata of params (which must
+// have been fetched with decryption) for a backup.
+func SnapshotParameters(sess *session.Session, params []*ssm.Parameter) ([]BackupParameter, error) {
+	names := []string{}
+	for _, param := range params {
+		names = append(names, *param.Name)
+	}
+
+	metadata, err := DescribeParameterNames(sess, names)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := []BackupParameter{}
+
+	for _, param := range params {
+		b := BackupParameter{Name: *param.Name, Type: *param.Type, Value: *param.Value}
+
+		if meta, ok := metadata[b.Name]; ok {
+			b.Description = aws.StringValue(meta.Description)
+			b.KeyID = aws.StringValue(meta.KeyId)
+			b.Tier = aws.StringValue(meta.Tier)
+			b.AllowedPattern = aws.StringValue(meta.AllowedPattern)
+		}
+
+		b.Tags, err = ListTags(sess, b.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		snapshot = append(snapshot, b)
+	}
+
+	return snapshot, nil
+}
+
+// WriteBackup encrypts params under a fresh data key from the KMS key keyID
+// and writes them to path, readable only by the current user.
+func WriteBackup(sess *session.Session, keyID, path string, params []BackupParameter) error {
+	plaintext, err := json.Marshal(backupContents{CreatedAt: time.Now().UTC(), Parameters: params})
+	if err != nil {
+		return err
+	}
+
+	dataKey, err := kms.New(sess).GenerateDataKey(&kms.GenerateDataKeyInput{
+		KeyId:   aws.String(keyID),
+		KeySpec: aws.String(kms.DataKeySpecAes256),
+	})
+	if err != nil {
+		return err
+	}
+
+	gcm, err := newGCM(dataKey.Plaintext)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	file := backupFile{
+		Version:      backupFormatVersion,
+		EncryptedKey: dataKey.CiphertextBlob,
+		Nonce:        nonce,
+		Ciphertext:   gcm.Seal(nil, nonce, plaintext, nil),
+	}
+
+	bytes, _ := json.MarshalIndent(file, "", "  ")
+	return ioutil.WriteFile(path, bytes, 0600)
+}
+
+func ReadBackup(sess *session.Session, path string) ([]BackupParameter, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file := backupFile{}
+	if err := json.Unmarshal(bytes, &file); err != nil {
+		return nil, err
+	}
+	if file.Version != backupFormatVersion {
+		return nil, fmt.Errorf("unsupported backup version %d", file.Version)
+	}
+
+	dataKey, err := kms.New(sess).Decrypt(&kms.DecryptInput{CiphertextBlob: file.EncryptedKey})
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(dataKey.Plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := backupContents{}
+	err = json.Unmarshal(plaintext, &contents)
+	return contents.Parameters, err
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,170 @@
+package pstore
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionUnchanged = "unchanged"
+)
+
+// Change is a write that import, copy and friends plan before making.
+type Change struct {
+	Action string
+	Put    PutRequest
+	Detail string // why an update is needed, never the value itself
+}
+
+// PlanChange compares the desired state of a parameter with its current
+// state (nil if it doesn't exist) and works out what needs to happen. The
+// description, KMS key and tier are compared when meta is given and tags
+// when tags is, but only those put sets, as PutParameter leaves the rest
+// alone.
+func PlanChange(put PutRequest, current *ssm.Parameter, meta *ssm.ParameterMetadata, tags map[string]string) Change {
+	if current == nil {
+		return Change{Action: ActionCreate, Put: put}
+	}
+
+	put.Overwrite = true
+	details := []string{}
+
+	if *current.Type != put.Type {
+		details = append(details, fmt.Sprintf("type %s → %s", *current.Type, put.Type))
+	}
+	if *current.Value != put.Value {
+		details = append(details, "value")
+	}
+
+	if meta != nil {
+		if put.Description != "" && put.Description != aws.StringValue(meta.Description) {
+			details = append(details, "description")
+		}
+		if keyID := aws.StringValue(meta.KeyId); put.Type == ssm.ParameterTypeSecureString && put.KeyID != "" && put.KeyID != keyID {
+			details = append(details, fmt.Sprintf("key %s → %s", keyID, put.KeyID))
+		}
+		// intelligent tiering picks the tier itself, so there's nothing to compare
+		if tier := aws.StringValue(meta.Tier); put.Tier != "" && put.Tier != ssm.ParameterTierIntelligentTiering && put.Tier != tier {
+			details = append(details, fmt.Sprintf("tier %s → %s", tier, put.Tier))
+		}
+	}
+
+	if tags != nil {
+		for key, value := range put.Tags {
+			if have, ok := tags[key]; !ok || have != value {
+				details = append(details, "tags")
+				break
+			}
+		}
+	}
+
+	if len(details) == 0 {
+		return Change{Action: ActionUnchanged, Put: put}
+	}
+
+	return Change{Action: ActionUpdate, Put: put, Detail: strings.Join(details, ", ")}
+}
+
+// ApplyChanges makes the creates and updates in changes, at most concurrency
+// at a time and at most perSecond each second so as to stay under Parameter
+// Store's put throughput limit. done is called (serially) after each one.
+func ApplyChanges(sess *session.Session, changes []Change, concurrency, perSecond int, done func(Change, error)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if perSecond < 1 {
+		perSecond = 1
+	}
+
+	pending := make(chan Change)
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for change := range pending {
+				_, err := PutParameter(sess, change.Put)
+				mu.Lock()
+				done(change, err)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	tick := time.NewTicker(time.Second / time.Duration(perSecond))
+	defer tick.Stop()
+
+	for _, change := range changes {
+		if change.Action == ActionCreate || change.Action == ActionUpdate {
+			<-tick.C
+			pending <- change
+		}
+	}
+
+	close(pending)
+	wg.Wait()
+}
+
+// TypeRule assigns a parameter type to keys matching a glob pattern.
+type TypeRule struct {
+	Pattern string
+	Type    string
+}
+
+// DefaultTypeRules treat the usual suspects as secrets.
+var DefaultTypeRules = []string{
+	"*_PASSWORD=" + ssm.ParameterTypeSecureString,
+	"*_SECRET=" + ssm.ParameterTypeSecureString,
+	"*_TOKEN=" + ssm.ParameterTypeSecureString,
+	"*_KEY=" + ssm.ParameterTypeSecureString,
+}
+
+// ParseTypeRules parses rules in GLOB=TYPE form, e.g. *_PASSWORD=SecureString.
+func ParseTypeRules(rules []string) ([]TypeRule, error) {
+	parsed := []TypeRule{}
+
+	for _, rule := range rules {
+		pair := strings.SplitN(rule, "=", 2)
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("type rule '%s' should be in GLOB=TYPE form", rule)
+		}
+		if _, err := path.Match(pair[0], ""); err != nil {
+			return nil, fmt.Errorf("type rule '%s': %s", rule, err)
+		}
+
+		switch pair[1] {
+		case ssm.ParameterTypeString, ssm.ParameterTypeStringList, ssm.ParameterTypeSecureString:
+		default:
+			return nil, fmt.Errorf("type rule '%s': unknown type '%s'", rule, pair[1])
+		}
+
+		parsed = append(parsed, TypeRule{Pattern: pair[0], Type: pair[1]})
+	}
+
+	return parsed, nil
+}
+
+// TypeFor returns the type of the first rule matching key, or fallback. Only
+// the last segment of a nested key is matched.
+func TypeFor(rules []TypeRule, key, fallback string) string {
+	key = key[strings.LastIndex(key, "/")+1:]
+
+	for _, rule := range rules {
+		if ok, _ := path.Match(rule.Pattern, key); ok {
+			return rule.Type
+		}
+	}
+
+	return fallback
+}
@@ -0,0 +1,51 @@
+package pstore
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+func TestPlanChange(t *testing.T) {
+	current := &ssm.Parameter{Name: aws.String("/app/KEY"), Type: aws.String(ssm.ParameterTypeSecureString), Value: aws.String("v")}
+	meta := &ssm.ParameterMetadata{
+		Description: aws.String("old"),
+		KeyId:       aws.String("alias/old"),
+		Tier:        aws.String(ssm.ParameterTierStandard),
+	}
+	same := PutRequest{Name: "/app/KEY", Type: ssm.ParameterTypeSecureString, Value: "v"}
+
+	tests := []struct {
+		name   string
+		put    func(p PutRequest) PutRequest
+		tags   map[string]string
+		action string
+		detail string
+	}{
+		{"unchanged", func(p PutRequest) PutRequest { return p }, nil, ActionUnchanged, ""},
+		{"value", func(p PutRequest) PutRequest { p.Value = "w"; return p }, nil, ActionUpdate, "value"},
+		{"description", func(p PutRequest) PutRequest { p.Description = "new"; return p }, nil, ActionUpdate, "description"},
+		{"key", func(p PutRequest) PutRequest { p.KeyID = "alias/new"; return p }, nil, ActionUpdate, "key alias/old → alias/new"},
+		{"tier", func(p PutRequest) PutRequest { p.Tier = ssm.ParameterTierAdvanced; return p }, nil, ActionUpdate, "tier Standard → Advanced"},
+		{"tags", func(p PutRequest) PutRequest { p.Tags = map[string]string{"team": "a"}; return p }, map[string]string{"team": "b"}, ActionUpdate, "tags"},
+		{"extra target tags", func(p PutRequest) PutRequest { p.Tags = map[string]string{"team": "a"}; return p }, map[string]string{"team": "a", "x": "y"}, ActionUnchanged, ""},
+		{"same settings", func(p PutRequest) PutRequest {
+			p.Description, p.KeyID, p.Tier = "old", "alias/old", ssm.ParameterTierStandard
+			return p
+		}, nil, ActionUnchanged, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change := PlanChange(tt.put(same), current, meta, tt.tags)
+			if change.Action != tt.action || change.Detail != tt.detail {
+				t.Errorf("got %s (%s), want %s (%s)", change.Action, change.Detail, tt.action, tt.detail)
+			}
+		})
+	}
+
+	if change := PlanChange(same, nil, nil, nil); change.Action != ActionCreate {
+		t.Errorf("got %s for a missing parameter, want %s", change.Action, ActionCreate)
+	}
+}
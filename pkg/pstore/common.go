@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -16,10 +17,13 @@ import (
 	"github.com/fatih/color"
 )
 
-const usageError = 64            // incorrect usage of "pstore"
-const pstoreError = 69           // parameter store issues
-const execError = 126            // cannot execute the specified command
-const commandNotFoundError = 127 // cannot find the specified command
+const UsageError = 64            // incorrect usage of "pstore"
+const DecryptError = 65          // a SecureString couldn't be decrypted
+const NotFoundError = 66         // the parameter or version doesn't exist
+const PstoreError = 69           // parameter store issues
+const AccessDeniedError = 77     // not allowed to read the parameter
+const ExecError = 126            // cannot execute the specified command
+const CommandNotFoundError = 127 // cannot find the specified command
 
 const appName = "pstore"
 
@@ -45,19 +49,27 @@ func GetParametersByTag(sess *session.Session, key, value string) []ParamResult
 	api := resourcegroupstaggingapi.New(sess)
 	api2 := ssm.New(sess)
 
-	resources, _ := api.GetResources(&resourcegroupstaggingapi.GetResourcesInput{
+	results := []ParamResult{}
+
+	resources, err := api.GetResources(&resourcegroupstaggingapi.GetResourcesInput{
 		TagFilters: []*resourcegroupstaggingapi.TagFilter{
 			{Key: &key, Values: aws.StringSlice([]string{value})},
 		},
 		ResourceTypeFilters: aws.StringSlice([]string{"ssm:parameter"}),
 	})
-
-	results := []ParamResult{}
+	if err != nil {
+		return append(results, ParamResult{
+			ParamName: key + "=" + value,
+			Source:    SourceTag,
+			Success:   false,
+			Err:       err,
+		})
+	}
 
 	for _, r := range resources.ResourceTagMappingList {
 		split := strings.SplitN(*r.ResourceARN, "parameter", 2)
 		name := split[1]
-		envName := TagValueWithKey(r.Tags, "pstore:name")
+		envName := TagValueWithKey(r.Tags, EnvNameTag)
 
 		if envName == nil {
 			continue
@@ -66,18 +78,37 @@ func GetParametersByTag(sess *session.Session, key, value string) []ParamResult
 		requestId := ""
 		input := &ssm.GetParametersInput{Names: aws.StringSlice([]string{name}), WithDecryption: aws.Bool(true)}
 
+		start := time.Now()
 		resp, err := api2.GetParametersWithContext(context.Background(), input, func(r *request.Request) {
 			r.Handlers.Complete.PushBack(func(req *request.Request) {
 				requestId = req.RequestID
 			})
 		})
+		latency := time.Since(start)
+
+		if err != nil {
+			results = append(results, ParamResult{
+				ParamName: name,
+				EnvName:   *envName,
+				Source:    SourceTag,
+				RequestID: requestId,
+				Latency:   latency,
+				Success:   false,
+				Err:       err,
+			})
+			continue
+		}
 
 		for _, p := range resp.Parameters {
 			result := ParamResult{
 				ParamName: *p.Name,
 				EnvName:   *envName,
 				Value:     *p.Value,
+				Version:   *p.Version,
+				Type:      *p.Type,
+				Source:    SourceTag,
 				RequestID: requestId,
+				Latency:   latency,
 				Success:   true,
 				Err:       err,
 			}
@@ -88,7 +119,9 @@ func GetParametersByTag(sess *session.Session, key, value string) []ParamResult
 			result := ParamResult{
 				ParamName: *name,
 				EnvName:   *envName,
+				Source:    SourceTag,
 				RequestID: requestId,
+				Latency:   latency,
 				Success:   false,
 				Err:       err,
 			}
@@ -100,11 +133,22 @@ func GetParametersByTag(sess *session.Session, key, value string) []ParamResult
 	return results
 }
 
+// Where a parameter reference came from
+const (
+	SourceName = "name"
+	SourcePath = "path"
+	SourceTag  = "tag"
+)
+
 type ParamResult struct {
 	ParamName string
 	EnvName   string
 	Value     string
+	Version   int64
+	Type      string
+	Source    string
 	RequestID string
+	Latency   time.Duration
 	Success   bool
 	Err       error
 }
@@ -117,18 +161,24 @@ func GetParamsByNames(sess *session.Session, input map[string]string) []ParamRes
 		requestID := ""
 
 		input := &ssm.GetParameterInput{Name: &paramName, WithDecryption: aws.Bool(true)}
+		start := time.Now()
 		resp, err := api2.GetParameterWithContext(context.Background(), input, func(r *request.Request) {
 			r.Handlers.Complete.PushBack(func(req *request.Request) {
 				requestID = req.RequestID
 			})
 		})
+		latency := time.Since(start)
 
 		if err == nil {
 			result := ParamResult{
 				ParamName: paramName,
 				EnvName:   envName,
 				Value:     *resp.Parameter.Value,
+				Version:   *resp.Parameter.Version,
+				Type:      *resp.Parameter.Type,
+				Source:    SourceName,
 				RequestID: requestID,
+				Latency:   latency,
 				Success:   true,
 				Err:       nil,
 			}
@@ -137,7 +187,9 @@ func GetParamsByNames(sess *session.Session, input map[string]string) []ParamRes
 			result := ParamResult{
 				ParamName: paramName,
 				EnvName:   envName,
+				Source:    SourceName,
 				RequestID: requestID,
+				Latency:   latency,
 				Success:   false,
 				Err:       err,
 			}
@@ -158,17 +210,20 @@ func GetParamsByPaths(sess *session.Session, input []string) []ParamResult {
 			Recursive:      aws.Bool(true),
 			WithDecryption: aws.Bool(true),
 		}
-		api.GetParametersByPathPagesWithContext(
+		start := time.Now()
+		found := len(results)
+		err := api.GetParametersByPathPagesWithContext(
 			context.Background(),
 			input,
 			func(page *ssm.GetParametersByPathOutput, lastPage bool) bool {
 				for _, param := range page.Parameters {
-					parts := strings.Split(*param.Name, "/")
-					name := parts[len(parts)-1]
 					results = append(results, ParamResult{
-						ParamName: "",
-						EnvName:   name,
+						ParamName: *param.Name,
+						EnvName:   PathEnvName(*param.Name),
 						Value:     *param.Value,
+						Version:   *param.Version,
+						Type:      *param.Type,
+						Source:    SourcePath,
 						RequestID: requestID,
 						Success:   true,
 						Err:       nil,
@@ -182,17 +237,55 @@ func GetParamsByPaths(sess *session.Session, input []string) []ParamResult {
 				})
 
 			})
+
+		latency := time.Since(start)
+		for idx := found; idx < len(results); idx++ {
+			results[idx].Latency = latency
+		}
+
+		if err != nil {
+			results = append(results, ParamResult{
+				ParamName: path,
+				Source:    SourcePath,
+				RequestID: requestID,
+				Latency:   latency,
+				Success:   false,
+				Err:       err,
+			})
+		}
 	}
 
 	return results
 }
 
+// PathEnvName is the env var a parameter found under a PSTOREPATH_ path is
+// exposed as: the last segment of its name.
+func PathEnvName(name string) string {
+	parts := strings.Split(name, "/")
+	return parts[len(parts)-1]
+}
+
 type ParamsRequest struct {
 	SimpleParams map[string]string
 	TaggedParams map[string]string
 	PathParams   []string
 }
 
+func (req ParamsRequest) Empty() bool {
+	return len(req.TaggedParams)+len(req.SimpleParams)+len(req.PathParams) == 0
+}
+
+// Options holds the settings shared by every command that resolves
+// parameters from the environment.
+type Options struct {
+	SimplePrefix string
+	TagPrefix    string
+	PathPrefix   string
+	Verbose      bool
+	LogFormat    string // "text" or "json"
+	ReportFile   string // where to write an audit report, if anywhere
+}
+
 func GetParamRequestFromEnv(simplePrefix, tagPrefix, pathPrefix string) ParamsRequest {
 	req := ParamsRequest{
 		SimpleParams: make(map[string]string),
@@ -218,56 +311,77 @@ func GetParamRequestFromEnv(simplePrefix, tagPrefix, pathPrefix string) ParamsRe
 	return req
 }
 
-func printErrors(params []ParamResult, verbose bool) bool {
-	anyFailed := false
-
-	for _, param := range params {
-		if !param.Success {
-			color.Red("✗ Failed to decrypt %s=%s (request ID: %s)", param.ParamName, param.EnvName, param.RequestID)
-			if param.Err != nil {
-				color.Red("Failed Reason: %s", param.Err.Error())
-			}
-			anyFailed = true
-		} else if verbose {
-			color.Green("✔ Decrypted %s︎=%s (request ID: %s)", param.ParamName, param.EnvName, param.RequestID)
-		}
-	}
+// Where awsRegion found the region
+const (
+	RegionFromMetadata = "EC2 instance metadata"
+	RegionFromEnv      = "AWS_REGION env var"
+)
 
-	return !anyFailed
+func awsRegion() string {
+	region, _, _ := resolveRegion()
+	return region
 }
 
-func awsRegion() string {
+// resolveRegion returns the region along with where it came from and
+// whether the EC2 instance metadata service could be reached.
+func resolveRegion() (region, source string, imdsAvailable bool) {
 	config := aws.NewConfig().
 		WithHTTPClient(&http.Client{Timeout: 2 * time.Second}).
 		WithMaxRetries(1)
 
 	meta := ec2metadata.New(session.Must(session.NewSession(config)))
-	region := os.Getenv("AWS_REGION")
+	region = os.Getenv("AWS_REGION")
+	if region != "" {
+		source = RegionFromEnv
+	}
 
-	if meta.Available() {
+	imdsAvailable = meta.Available()
+	if imdsAvailable {
 		regionp, err := meta.Region()
 		if err == nil {
 			region = regionp
+			source = RegionFromMetadata
 		}
 	}
 
-	return region
+	return region, source, imdsAvailable
 }
 
-func Doit(simplePrefix, tagPrefix, pathPrefix string, verbose bool, callback func(key, value string)) {
-	req := GetParamRequestFromEnv(simplePrefix, tagPrefix, pathPrefix)
-	if len(req.TaggedParams)+len(req.SimpleParams)+len(req.PathParams) == 0 {
-		return
-	}
-
+// NewSession creates a session in the region pstore would use to resolve
+// parameters, aborting if there is none.
+func NewSession() *session.Session {
 	region := awsRegion()
 	if len(region) == 0 {
-		abort(usageError, "No AWS region specified. Either run on EC2 or specify AWS_REGION env var")
+		Abort(UsageError, "No AWS region specified. Either run on EC2 or specify AWS_REGION env var")
+	}
+
+	sess, _ := session.NewSession(aws.NewConfig().WithRegion(region))
+	sess.Handlers.Build.PushBackNamed(userAgentHandler)
+	return sess
+}
+
+// NewSessionFor creates a session in region (or the region pstore would
+// normally use if that's empty) that assumes role, if one is given. It is
+// used to reach parameters in other regions and accounts.
+func NewSessionFor(region, role string) *session.Session {
+	if region == "" {
+		region = awsRegion()
+	}
+	if region == "" {
+		Abort(UsageError, "No AWS region specified. Either run on EC2 or specify AWS_REGION env var")
 	}
 
 	sess, _ := session.NewSession(aws.NewConfig().WithRegion(region))
 	sess.Handlers.Build.PushBackNamed(userAgentHandler)
 
+	if role != "" {
+		sess = sess.Copy(aws.NewConfig().WithCredentials(stscreds.NewCredentials(sess, role)))
+	}
+
+	return sess
+}
+
+func fetchParams(sess *session.Session, req ParamsRequest) []ParamResult {
 	results := GetParamsByNames(sess, req.SimpleParams)
 
 	results = append(results, GetParamsByPaths(sess, req.PathParams)...)
@@ -276,16 +390,47 @@ func Doit(simplePrefix, tagPrefix, pathPrefix string, verbose bool, callback fun
 		results = append(results, GetParametersByTag(sess, key, val)...)
 	}
 
-	if !printErrors(results, verbose) {
-		abort(pstoreError, "Failed to decrypt some secret values")
+	return results
+}
+
+// Resolve fetches the parameters requested by the environment, aborting
+// if any of them can't be.
+func Resolve(opts Options) []ParamResult {
+	req := GetParamRequestFromEnv(opts.SimplePrefix, opts.TagPrefix, opts.PathPrefix)
+	if req.Empty() {
+		writeReport(nil, opts)
+		return nil
+	}
+
+	sess := NewSession()
+	results := fetchParams(sess, req)
+
+	if !reportResults(results, opts) {
+		Abort(PstoreError, "Failed to decrypt some secret values")
 	}
 
-	for _, param := range results {
+	return results
+}
+
+func Doit(simplePrefix, tagPrefix, pathPrefix string, verbose bool, callback func(key, value string)) {
+	DoitWithOptions(Options{
+		SimplePrefix: simplePrefix,
+		TagPrefix:    tagPrefix,
+		PathPrefix:   pathPrefix,
+		Verbose:      verbose,
+		LogFormat:    LogFormatText,
+	}, callback)
+}
+
+// DoitWithOptions is Doit with control over how diagnostics are logged and
+// reported.
+func DoitWithOptions(opts Options, callback func(key, value string)) {
+	for _, param := range Resolve(opts) {
 		callback(param.EnvName, param.Value)
 	}
 }
 
-func abort(status int, message interface{}) {
+func Abort(status int, message interface{}) {
 	color.New(color.FgRed).Fprintf(os.Stderr, "ERROR: %s\n", message)
 	os.Exit(status)
 }
@@ -0,0 +1,124 @@
+package pstore
+
+import (
+	"path"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// CopyRequest describes a tree of parameters to copy, possibly between
+// regions or accounts.
+type CopyRequest struct {
+	Source     *session.Session
+	SourcePath string
+	Target     *session.Session
+	TargetPath string
+	Include    []string          // globs matched against relative names, empty means all
+	Exclude    []string          // globs matched against relative names
+	KeyMap     map[string]string // source KMS key id to target KMS key id
+}
+
+// PlanCopy works out the changes needed to make the target path match the
+// (filtered) source path. Types, KMS keys, tiers, descriptions, allowed
+// patterns and tags are all carried across. Nothing is ever deleted.
+func PlanCopy(req CopyRequest) ([]Change, error) {
+	source, err := GetParameterTree(req.Source, req.SourcePath, true, true)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := DescribeParameterTree(req.Source, req.SourcePath, true)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := GetParameterTree(req.Target, req.TargetPath, true, true)
+	if err != nil {
+		return nil, err
+	}
+
+	targetMetadata, err := DescribeParameterTree(req.Target, req.TargetPath, true)
+	if err != nil {
+		return nil, err
+	}
+
+	current := map[string]*ssm.Parameter{}
+	for _, param := range target {
+		current[*param.Name] = param
+	}
+
+	changes := []Change{}
+
+	for _, param := range source {
+		relative := RelativeName(req.SourcePath, *param.Name)
+		if !matchesAny(req.Include, relative, true) || matchesAny(req.Exclude, relative, false) {
+			continue
+		}
+
+		put := PutRequest{
+			Name:  JoinName(req.TargetPath, relative),
+			Value: *param.Value,
+			Type:  *param.Type,
+		}
+
+		if meta, ok := metadata[*param.Name]; ok {
+			put.Description = aws.StringValue(meta.Description)
+			put.AllowedPattern = aws.StringValue(meta.AllowedPattern)
+			put.Tier = aws.StringValue(meta.Tier)
+			put.KeyID = aws.StringValue(meta.KeyId)
+			if mapped, ok := req.KeyMap[put.KeyID]; ok {
+				put.KeyID = mapped
+			}
+		}
+
+		put.Tags, err = ListTags(req.Source, *param.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		var targetTags map[string]string
+		if current[put.Name] != nil && len(put.Tags) > 0 {
+			targetTags, err = ListTags(req.Target, put.Name)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		changes = append(changes, PlanChange(put, current[put.Name], targetMetadata[put.Name], targetTags))
+	}
+
+	return changes, nil
+}
+
+func ListTags(sess *session.Session, name string) (map[string]string, error) {
+	resp, err := ssm.New(sess).ListTagsForResource(&ssm.ListTagsForResourceInput{
+		ResourceType: aws.String(ssm.ResourceTypeForTaggingParameter),
+		ResourceId:   aws.String(name),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tags := map[string]string{}
+	for _, tag := range resp.TagList {
+		tags[*tag.Key] = *tag.Value
+	}
+
+	return tags, nil
+}
+
+func matchesAny(patterns []string, name string, emptyMatches bool) bool {
+	if len(patterns) == 0 {
+		return emptyMatches
+	}
+
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,42 @@
+package pstore
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// DeleteParameters accepts at most this many names per call
+const deleteParametersBatchSize = 10
+
+var ErrParameterNotFound = errors.New("parameter not found")
+
+// DeleteParameters deletes names in batches, calling done once for every
+// name with the outcome of deleting it.
+func DeleteParameters(sess *session.Session, names []string, done func(name string, err error)) {
+	api := ssm.New(sess)
+
+	for start := 0; start < len(names); start += deleteParametersBatchSize {
+		end := start + deleteParametersBatchSize
+		if end > len(names) {
+			end = len(names)
+		}
+
+		resp, err := api.DeleteParameters(&ssm.DeleteParametersInput{Names: aws.StringSlice(names[start:end])})
+		if err != nil {
+			for _, name := range names[start:end] {
+				done(name, err)
+			}
+			continue
+		}
+
+		for _, name := range resp.DeletedParameters {
+			done(*name, nil)
+		}
+		for _, name := range resp.InvalidParameters {
+			done(*name, ErrParameterNotFound)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package pstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/fatih/color"
+)
+
+// Diagnostics are always written to stderr: stdout belongs to the eval-able
+// output of shell and powershell.
+
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// ValidLogFormat returns an error unless format is text or json.
+func ValidLogFormat(format string) error {
+	if format != LogFormatText && format != LogFormatJSON {
+		return fmt.Errorf("--log-format should be %s or %s, not '%s'", LogFormatText, LogFormatJSON, format)
+	}
+	return nil
+}
+
+// ParamEvent describes the outcome of resolving one parameter. It never
+// carries the parameter's value.
+type ParamEvent struct {
+	Time          time.Time `json:"time"`
+	Level         string    `json:"level"`
+	EnvName       string    `json:"env_name"`
+	ParamName     string    `json:"parameter_name"`
+	Source        string    `json:"source"`
+	Version       int64     `json:"version,omitempty"`
+	Type          string    `json:"type,omitempty"`
+	RequestID     string    `json:"request_id,omitempty"`
+	LatencyMillis int64     `json:"latency_ms"`
+	ErrorClass    string    `json:"error_class,omitempty"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// Kinds of WatchEvent
+const (
+	WatchChanged    = "changed"
+	WatchPollFailed = "poll_failed"
+	WatchSignal     = "signal"
+	WatchRestart    = "restart"
+)
+
+// WatchEvent describes something exec --watch noticed or did.
+type WatchEvent struct {
+	Time      time.Time `json:"time"`
+	Level     string    `json:"level"`
+	Event     string    `json:"event"`
+	Message   string    `json:"message"`
+	ParamName string    `json:"parameter_name,omitempty"`
+	Version   int64     `json:"version,omitempty"`
+}
+
+// logWatchEvent writes event to stderr in the given format.
+func logWatchEvent(format string, event WatchEvent) {
+	event.Time = time.Now().UTC()
+	if event.Level == "" {
+		event.Level = "info"
+	}
+
+	if format == LogFormatJSON {
+		json.NewEncoder(os.Stderr).Encode(event)
+		return
+	}
+
+	color.New(color.FgYellow).Fprintf(os.Stderr, "↻ %s\n", event.Message)
+}
+
+type Report struct {
+	GeneratedAt time.Time    `json:"generated_at"`
+	Total       int          `json:"total"`
+	Succeeded   int          `json:"succeeded"`
+	Failed      int          `json:"failed"`
+	Parameters  []ParamEvent `json:"parameters"`
+}
+
+func NewParamEvent(param ParamResult) ParamEvent {
+	event := ParamEvent{
+		Time:          time.Now().UTC(),
+		Level:         "info",
+		EnvName:       param.EnvName,
+		ParamName:     param.ParamName,
+		Source:        param.Source,
+		Version:       param.Version,
+		Type:          param.Type,
+		RequestID:     param.RequestID,
+		LatencyMillis: param.Latency.Nanoseconds() / int64(time.Millisecond),
+	}
+
+	if !param.Success {
+		event.Level = "error"
+		event.ErrorClass = errorClass(param)
+		if param.Err != nil {
+			event.Error = param.Err.Error()
+		}
+	}
+
+	return event
+}
+
+// errorClass is the AWS error code behind a failure, e.g. AccessDeniedException.
+func errorClass(param ParamResult) string {
+	if param.Err == nil {
+		// GetParameters reports missing names without an error
+		return "ParameterNotFound"
+	}
+
+	if aerr, ok := param.Err.(awserr.Error); ok {
+		return aerr.Code()
+	}
+
+	return "Unknown"
+}
+
+func succeeded(params []ParamResult) bool {
+	for _, param := range params {
+		if !param.Success {
+			return false
+		}
+	}
+	return true
+}
+
+// logResults writes one line per parameter to stderr. Text output only
+// mentions successful parameters when verbose is set, JSON output always
+// includes them.
+func logResults(params []ParamResult, format string, verbose bool) bool {
+	if format == LogFormatJSON {
+		encoder := json.NewEncoder(os.Stderr)
+		for _, param := range params {
+			encoder.Encode(NewParamEvent(param))
+		}
+		return succeeded(params)
+	}
+
+	red := color.New(color.FgRed)
+	green := color.New(color.FgGreen)
+
+	for _, param := range params {
+		if !param.Success {
+			red.Fprintf(os.Stderr, "✗ Failed to decrypt %s=%s (request ID: %s)\n", param.ParamName, param.EnvName, param.RequestID)
+			if param.Err != nil {
+				red.Fprintf(os.Stderr, "Failed Reason: %s\n", param.Err.Error())
+			}
+		} else if verbose {
+			green.Fprintf(os.Stderr, "✔ Decrypted %s︎=%s (request ID: %s)\n", param.ParamName, param.EnvName, param.RequestID)
+		}
+	}
+
+	return succeeded(params)
+}
+
+// reportResults logs the outcome of the initial resolution and writes the
+// audit report if one was asked for.
+func reportResults(params []ParamResult, opts Options) bool {
+	ok := logResults(params, opts.LogFormat, opts.Verbose)
+	writeReport(params, opts)
+	return ok
+}
+
+func writeReport(params []ParamResult, opts Options) {
+	if opts.ReportFile == "" {
+		return
+	}
+
+	report := Report{
+		GeneratedAt: time.Now().UTC(),
+		Parameters:  []ParamEvent{},
+	}
+
+	for _, param := range params {
+		event := NewParamEvent(param)
+		report.Parameters = append(report.Parameters, event)
+		report.Total++
+		if param.Success {
+			report.Succeeded++
+		} else {
+			report.Failed++
+		}
+	}
+
+	bytes, _ := json.MarshalIndent(report, "", "  ")
+	if err := ioutil.WriteFile(opts.ReportFile, append(bytes, '\n'), 0644); err != nil {
+		Abort(UsageError, err)
+	}
+}
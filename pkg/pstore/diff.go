@@ -0,0 +1,99 @@
+package pstore
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// DiffEntry is a key that differs between two parameter trees. Values are
+// only filled in for the side(s) the key exists on.
+type DiffEntry struct {
+	Key          string `json:"key"`
+	Status       string `json:"status"`
+	SourceType   string `json:"source_type,omitempty"`
+	TargetType   string `json:"target_type,omitempty"`
+	SourceValue  string `json:"source_value,omitempty"`
+	TargetValue  string `json:"target_value,omitempty"`
+	ValueChanged bool   `json:"value_changed"`
+	TypeChanged  bool   `json:"type_changed"`
+}
+
+// DiffTrees compares the parameters under sourcePath with those under
+// targetPath by name relative to each path. Keys present in target but not
+// source are "added".
+func DiffTrees(sourcePath string, source []*ssm.Parameter, targetPath string, target []*ssm.Parameter) []DiffEntry {
+	sources := map[string]*ssm.Parameter{}
+	for _, param := range source {
+		sources[RelativeName(sourcePath, *param.Name)] = param
+	}
+
+	targets := map[string]*ssm.Parameter{}
+	for _, param := range target {
+		targets[RelativeName(targetPath, *param.Name)] = param
+	}
+
+	entries := []DiffEntry{}
+
+	for key, s := range sources {
+		t, ok := targets[key]
+		if !ok {
+			entries = append(entries, DiffEntry{Key: key, Status: DiffRemoved, SourceType: *s.Type, SourceValue: *s.Value})
+			continue
+		}
+
+		entry := DiffEntry{
+			Key:          key,
+			Status:       DiffChanged,
+			SourceType:   *s.Type,
+			TargetType:   *t.Type,
+			SourceValue:  *s.Value,
+			TargetValue:  *t.Value,
+			ValueChanged: *s.Value != *t.Value,
+			TypeChanged:  *s.Type != *t.Type,
+		}
+
+		if entry.ValueChanged || entry.TypeChanged {
+			entries = append(entries, entry)
+		}
+	}
+
+	for key, t := range targets {
+		if _, ok := sources[key]; !ok {
+			entries = append(entries, DiffEntry{Key: key, Status: DiffAdded, TargetType: *t.Type, TargetValue: *t.Value})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+
+	return entries
+}
+
+// NewValueHasher returns a function whose result stands in for a value that
+// shouldn't be shown but still needs to be told apart from other values. It
+// is an HMAC keyed with random bytes, so short secrets can't be brute-forced
+// from it, and hashes can only be compared within a single run.
+func NewValueHasher() func(value string) string {
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		Abort(PstoreError, err)
+	}
+
+	return func(value string) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(value))
+		return fmt.Sprintf("hmac:%x", mac.Sum(nil))[:17]
+	}
+}
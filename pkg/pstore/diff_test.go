@@ -0,0 +1,20 @@
+package pstore
+
+import "testing"
+
+func TestValueHasher(t *testing.T) {
+	hash := NewValueHasher()
+
+	if hash("1234") != hash("1234") {
+		t.Error("equal values should hash the same within a run")
+	}
+	if hash("1234") == hash("1235") {
+		t.Error("different values should hash differently")
+	}
+	if got := hash("1234"); len(got) != len("hmac:")+12 {
+		t.Errorf("got %q, want hmac: and 12 hex characters", got)
+	}
+	if NewValueHasher()("1234") == hash("1234") {
+		t.Error("hashes from different runs should differ")
+	}
+}
@@ -0,0 +1,175 @@
+package pstore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// Check is the outcome of one of the tests run by Diagnose.
+type Check struct {
+	Name   string
+	OK     bool
+	Detail string
+}
+
+// Diagnose works through everything pstore needs to resolve the references
+// in the environment - region, credentials and permissions - passing each
+// result to report as soon as it is known. It returns false if any failed.
+func Diagnose(opts Options, report func(Check)) bool {
+	ok := true
+	check := func(c Check) bool {
+		ok = ok && c.OK
+		report(c)
+		return c.OK
+	}
+
+	region, source, imds := resolveRegion()
+
+	if imds {
+		check(Check{Name: "Instance metadata", OK: true, Detail: "reachable"})
+	} else {
+		check(Check{Name: "Instance metadata", OK: true, Detail: "not reachable, this doesn't look like EC2"})
+	}
+
+	if region == "" {
+		check(Check{Name: "Region", Detail: "no region found. Either run on EC2 or specify AWS_REGION env var"})
+		return false
+	}
+	check(Check{Name: "Region", OK: true, Detail: fmt.Sprintf("%s (from %s)", region, source)})
+
+	sess, err := session.NewSession(aws.NewConfig().WithRegion(region))
+	if err != nil {
+		check(Check{Name: "Session", Detail: err.Error()})
+		return false
+	}
+	sess.Handlers.Build.PushBackNamed(userAgentHandler)
+
+	creds, err := sess.Config.Credentials.Get()
+	if err != nil {
+		check(Check{Name: "Credentials", Detail: explain(err, "", "")})
+		return false
+	}
+	check(Check{Name: "Credentials", OK: true, Detail: "from " + creds.ProviderName})
+
+	identity, err := sts.New(sess).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		check(Check{Name: "Caller identity", Detail: explain(err, "sts:GetCallerIdentity", "")})
+		return false
+	}
+	check(Check{Name: "Caller identity", OK: true, Detail: *identity.Arn})
+
+	req := GetParamRequestFromEnv(opts.SimplePrefix, opts.TagPrefix, opts.PathPrefix)
+	if req.Empty() {
+		check(Check{Name: "References", OK: true, Detail: fmt.Sprintf("no env vars with %s, %s or %s prefixes to test", opts.SimplePrefix, opts.TagPrefix, opts.PathPrefix)})
+		return ok
+	}
+
+	api := ssm.New(sess)
+
+	for envName, name := range req.SimpleParams {
+		label := fmt.Sprintf("%s%s=%s", opts.SimplePrefix, envName, name)
+
+		resp, err := api.GetParameter(&ssm.GetParameterInput{Name: aws.String(name), WithDecryption: aws.Bool(false)})
+		if !check(result(label, "ssm:GetParameter", name, err)) || *resp.Parameter.Type != ssm.ParameterTypeSecureString {
+			continue
+		}
+
+		_, err = api.GetParameter(&ssm.GetParameterInput{Name: aws.String(name), WithDecryption: aws.Bool(true)})
+		check(result(label, "kms:Decrypt", name, err))
+	}
+
+	for _, path := range req.PathParams {
+		label := fmt.Sprintf("%s=%s", opts.PathPrefix, path)
+		input := &ssm.GetParametersByPathInput{Path: aws.String(path), Recursive: aws.Bool(true), WithDecryption: aws.Bool(false)}
+
+		_, err := api.GetParametersByPath(input)
+		if !check(result(label, "ssm:GetParametersByPath", path, err)) {
+			continue
+		}
+
+		_, err = api.GetParametersByPath(input.SetWithDecryption(true))
+		check(result(label, "kms:Decrypt", path, err))
+	}
+
+	tagging := resourcegroupstaggingapi.New(sess)
+
+	for key, value := range req.TaggedParams {
+		label := fmt.Sprintf("%s%s=%s", opts.TagPrefix, key, value)
+
+		resources, err := tagging.GetResources(&resourcegroupstaggingapi.GetResourcesInput{
+			TagFilters:          []*resourcegroupstaggingapi.TagFilter{{Key: aws.String(key), Values: aws.StringSlice([]string{value})}},
+			ResourceTypeFilters: aws.StringSlice([]string{"ssm:parameter"}),
+		})
+		if !check(result(label, "tag:GetResources", "*", err)) {
+			continue
+		}
+
+		// the values are fetched by name with ssm:GetParameters, so the
+		// tag lookup succeeding says nothing about being able to read them
+		for _, r := range resources.ResourceTagMappingList {
+			name := strings.SplitN(*r.ResourceARN, "parameter", 2)[1]
+			paramLabel := fmt.Sprintf("%s (%s)", label, name)
+			input := &ssm.GetParametersInput{Names: aws.StringSlice([]string{name}), WithDecryption: aws.Bool(false)}
+
+			resp, err := api.GetParameters(input)
+			if err == nil && len(resp.InvalidParameters) > 0 {
+				err = awserr.New(ssm.ErrCodeParameterNotFound, "parameter not found", nil)
+			}
+			if !check(result(paramLabel, "ssm:GetParameters", *r.ResourceARN, err)) || *resp.Parameters[0].Type != ssm.ParameterTypeSecureString {
+				continue
+			}
+
+			_, err = api.GetParameters(input.SetWithDecryption(true))
+			check(result(paramLabel, "kms:Decrypt", *r.ResourceARN, err))
+		}
+	}
+
+	return ok
+}
+
+func result(label, action, resource string, err error) Check {
+	if err != nil {
+		return Check{Name: label, Detail: explain(err, action, resource)}
+	}
+	return Check{Name: label, OK: true, Detail: action + " allowed"}
+}
+
+// explain turns an AWS error into an explanation of what is most likely
+// wrong, naming the missing permission when it is a permissions problem.
+func explain(err error, action, resource string) string {
+	aerr, ok := err.(awserr.Error)
+	if !ok {
+		return err.Error()
+	}
+
+	message := strings.ToLower(aerr.Message())
+
+	switch aerr.Code() {
+	case "NoCredentialProviders":
+		return "no credentials found. Set AWS_PROFILE or AWS_ACCESS_KEY_ID, or attach an instance profile or task role"
+	case "ExpiredToken", "ExpiredTokenException", "RequestExpired":
+		return "credentials have expired, refresh them and try again"
+	case "InvalidClientTokenId", "UnrecognizedClientException", "SignatureDoesNotMatch":
+		return "credentials were rejected by AWS, check they are valid and for the right account"
+	case "ParameterNotFound":
+		return fmt.Sprintf("parameter %s does not exist in this region", resource)
+	case "InvalidKeyId":
+		return fmt.Sprintf("the KMS key used to encrypt %s is disabled or doesn't exist", resource)
+	case "RequestError":
+		return "couldn't reach AWS: " + aerr.Message()
+	case "AccessDeniedException", "AccessDenied":
+		if strings.Contains(message, "kms") {
+			return fmt.Sprintf("missing kms:Decrypt permission on the key used to encrypt %s", resource)
+		}
+		return fmt.Sprintf("missing %s permission on %s", action, resource)
+	}
+
+	return fmt.Sprintf("%s: %s", aerr.Code(), aerr.Message())
+}
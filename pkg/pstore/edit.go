@@ -0,0 +1,66 @@
+package pstore
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"gopkg.in/yaml.v2"
+)
+
+// EditDocument renders the parameters under path as a YAML document keyed by
+// relative name, for editing by hand.
+func EditDocument(path string, params []*ssm.Parameter) ([]byte, error) {
+	doc := yaml.MapSlice{}
+	for _, param := range params {
+		doc = append(doc, yaml.MapItem{Key: RelativeName(path, *param.Name), Value: *param.Value})
+	}
+	return yaml.Marshal(doc)
+}
+
+// PlanEdit works out the changes needed to turn the parameters under path
+// into the edited document. Existing parameters keep their type, KMS key,
+// tier, description and allowed pattern. New ones are typed by rules. It
+// also returns the names of the parameters that were removed.
+func PlanEdit(path string, params []*ssm.Parameter, metadata map[string]*ssm.ParameterMetadata, edited []byte, rules []TypeRule) ([]Change, []string, error) {
+	values := map[string]string{}
+	if err := yaml.UnmarshalStrict(edited, &values); err != nil {
+		return nil, nil, err
+	}
+
+	current := map[string]*ssm.Parameter{}
+	for _, param := range params {
+		current[*param.Name] = param
+	}
+
+	changes := []Change{}
+
+	for key, value := range values {
+		put := PutRequest{Name: JoinName(path, key), Value: value}
+
+		if param, ok := current[put.Name]; ok {
+			put.Type = *param.Type
+		} else {
+			put.Type = TypeFor(rules, key, ssm.ParameterTypeString)
+		}
+
+		if meta, ok := metadata[put.Name]; ok {
+			put.KeyID = aws.StringValue(meta.KeyId)
+			put.Tier = aws.StringValue(meta.Tier)
+			put.Description = aws.StringValue(meta.Description)
+			put.AllowedPattern = aws.StringValue(meta.AllowedPattern)
+		}
+
+		changes = append(changes, PlanChange(put, current[put.Name], metadata[put.Name], nil))
+	}
+
+	deletes := []string{}
+	for _, param := range params {
+		if _, ok := values[RelativeName(path, *param.Name)]; !ok {
+			deletes = append(deletes, *param.Name)
+		}
+	}
+	sort.Strings(deletes)
+
+	return changes, deletes, nil
+}
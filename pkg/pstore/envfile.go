@@ -0,0 +1,147 @@
+package pstore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	FormatDotenv = "dotenv"
+	FormatJSON   = "json"
+	FormatYAML   = "yaml"
+)
+
+// ParseEnvFile reads key/value pairs in the given format. Nested JSON and
+// YAML objects are flattened into slash-separated keys, so they map onto
+// parameter hierarchies.
+func ParseEnvFile(format string, data []byte) (map[string]string, error) {
+	switch format {
+	case FormatDotenv:
+		return parseDotenv(data)
+	case FormatJSON:
+		doc := map[string]interface{}{}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&doc); err != nil {
+			return nil, err
+		}
+		return flatten(doc)
+	case FormatYAML:
+		doc := map[string]interface{}{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		return flatten(doc)
+	}
+
+	return nil, fmt.Errorf("unsupported format '%s'", format)
+}
+
+func parseDotenv(data []byte) (map[string]string, error) {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+		pair := strings.SplitN(line, "=", 2)
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNo)
+		}
+
+		key := strings.TrimSpace(pair[0])
+		value, err := unquoteDotenv(strings.TrimSpace(pair[1]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNo, err)
+		}
+
+		values[key] = value
+	}
+
+	return values, scanner.Err()
+}
+
+func unquoteDotenv(value string) (string, error) {
+	if len(value) == 0 {
+		return value, nil
+	}
+
+	switch value[0] {
+	case '\'':
+		end := strings.LastIndex(value, "'")
+		if end == 0 {
+			return "", fmt.Errorf("unterminated quote")
+		}
+		return value[1:end], nil
+	case '"':
+		out := strings.Builder{}
+		for i := 1; i < len(value); i++ {
+			switch c := value[i]; {
+			case c == '"':
+				return out.String(), nil
+			case c == '\\' && i+1 < len(value):
+				i++
+				switch value[i] {
+				case 'n':
+					out.WriteByte('\n')
+				case 'r':
+					out.WriteByte('\r')
+				case 't':
+					out.WriteByte('\t')
+				default:
+					out.WriteByte(value[i])
+				}
+			default:
+				out.WriteByte(c)
+			}
+		}
+		return "", fmt.Errorf("unterminated quote")
+	}
+
+	if idx := strings.Index(value, " #"); idx >= 0 {
+		value = strings.TrimSpace(value[:idx])
+	}
+
+	return value, nil
+}
+
+func flatten(doc map[string]interface{}) (map[string]string, error) {
+	values := map[string]string{}
+	return values, flattenInto(values, "", doc)
+}
+
+func flattenInto(values map[string]string, prefix string, node interface{}) error {
+	switch node := node.(type) {
+	case map[string]interface{}:
+		for key, child := range node {
+			if err := flattenInto(values, prefix+key+"/", child); err != nil {
+				return err
+			}
+		}
+	case map[interface{}]interface{}:
+		for key, child := range node {
+			if err := flattenInto(values, fmt.Sprintf("%s%v/", prefix, key), child); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		return fmt.Errorf("%s: lists aren't supported", strings.TrimSuffix(prefix, "/"))
+	case nil:
+		values[strings.TrimSuffix(prefix, "/")] = ""
+	default:
+		values[strings.TrimSuffix(prefix, "/")] = fmt.Sprint(node)
+	}
+
+	return nil
+}
@@ -9,15 +9,15 @@ import (
 
 func ExecCommand(args []string) {
 	if len(args) == 0 {
-		abort(usageError, "no command specified")
+		Abort(UsageError, "no command specified")
 	}
 	commandName := args[0]
 	commandPath, err := exec.LookPath(commandName)
 	if err != nil {
-		abort(commandNotFoundError, fmt.Sprintf("cannot find '%s'", commandName))
+		Abort(CommandNotFoundError, fmt.Sprintf("cannot find '%s'", commandName))
 	}
 	err = syscall.Exec(commandPath, args, os.Environ())
 	if err != nil {
-		abort(execError, err)
+		Abort(ExecError, err)
 	}
 }
@@ -8,7 +8,7 @@ import (
 
 func ExecCommand(args []string) {
 	if len(args) == 0 {
-		abort(usageError, "no command specified")
+		Abort(UsageError, "no command specified")
 	}
 
 	cmd := exec.Command(args[0], args[1:]...)
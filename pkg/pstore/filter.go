@@ -0,0 +1,75 @@
+package pstore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// TagFilter parses key=value, or a bare key to match any value, into a
+// DescribeParameters filter.
+func TagFilter(tag string) (*ssm.ParameterStringFilter, error) {
+	key, value := tag, ""
+	if idx := strings.Index(tag, "="); idx >= 0 {
+		key, value = tag[:idx], tag[idx+1:]
+	}
+
+	if key == "" {
+		return nil, fmt.Errorf("tag filter '%s' should be key=value or key", tag)
+	}
+
+	if value == "" {
+		return &ssm.ParameterStringFilter{Key: aws.String("tag-key"), Option: aws.String("Equals"), Values: aws.StringSlice([]string{key})}, nil
+	}
+
+	return &ssm.ParameterStringFilter{Key: aws.String("tag:" + key), Option: aws.String("Equals"), Values: aws.StringSlice([]string{value})}, nil
+}
+
+// TypeFilter matches parameters of any of the given types.
+func TypeFilter(types []string) (*ssm.ParameterStringFilter, error) {
+	for _, typ := range types {
+		if !validParameterType(typ) {
+			return nil, fmt.Errorf("type should be one of %s, not '%s'", strings.Join(ssm.ParameterType_Values(), ", "), typ)
+		}
+	}
+
+	return &ssm.ParameterStringFilter{Key: aws.String("Type"), Option: aws.String("Equals"), Values: aws.StringSlice(types)}, nil
+}
+
+func validParameterType(typ string) bool {
+	for _, valid := range ssm.ParameterType_Values() {
+		if typ == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// NameFilter matches parameters whose name contains term, or begins with
+// it when beginsWith is set.
+func NameFilter(term string, beginsWith bool) *ssm.ParameterStringFilter {
+	option := "Contains"
+	if beginsWith {
+		option = "BeginsWith"
+	}
+
+	return &ssm.ParameterStringFilter{Key: aws.String("Name"), Option: aws.String(option), Values: aws.StringSlice([]string{term})}
+}
+
+// KeyIDFilter matches SecureStrings encrypted with the given KMS key.
+func KeyIDFilter(keyID string) *ssm.ParameterStringFilter {
+	return &ssm.ParameterStringFilter{Key: aws.String("KeyId"), Option: aws.String("Equals"), Values: aws.StringSlice([]string{keyID})}
+}
+
+// TierFilter matches parameters in the given tier.
+func TierFilter(tier string) (*ssm.ParameterStringFilter, error) {
+	for _, valid := range ssm.ParameterTier_Values() {
+		if tier == valid {
+			return &ssm.ParameterStringFilter{Key: aws.String("Tier"), Option: aws.String("Equals"), Values: aws.StringSlice([]string{tier})}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("tier should be one of %s, not '%s'", strings.Join(ssm.ParameterTier_Values(), ", "), tier)
+}
@@ -0,0 +1,122 @@
+package pstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	FormatDockerEnv  = "docker-env"
+	FormatProperties = "properties"
+)
+
+// EnvVar is a resolved name and value, ready to be written out.
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// WriteEnv writes vars in the given format, sorted by name and escaped as
+// that format requires.
+func WriteEnv(w io.Writer, format string, vars []EnvVar) error {
+	vars = append([]EnvVar{}, vars...)
+	sort.SliceStable(vars, func(i, j int) bool {
+		return vars[i].Name < vars[j].Name
+	})
+
+	switch format {
+	case FormatDotenv:
+		for _, v := range vars {
+			fmt.Fprintf(w, "%s=\"%s\"\n", v.Name, escapeDotenv(v.Value))
+		}
+	case FormatJSON:
+		bytes, _ := json.MarshalIndent(envMap(vars), "", "  ")
+		fmt.Fprintln(w, string(bytes))
+	case FormatYAML:
+		bytes, err := yaml.Marshal(envMap(vars))
+		if err != nil {
+			return err
+		}
+		w.Write(bytes)
+	case FormatDockerEnv:
+		// docker takes everything after the = literally, so there's no way
+		// to represent a newline
+		for _, v := range vars {
+			if strings.ContainsAny(v.Value, "\r\n") {
+				return fmt.Errorf("%s contains a newline, which docker env files can't represent", v.Name)
+			}
+		}
+		for _, v := range vars {
+			fmt.Fprintf(w, "%s=%s\n", v.Name, v.Value)
+		}
+	case FormatProperties:
+		for _, v := range vars {
+			fmt.Fprintf(w, "%s=%s\n", escapeProperty(v.Name, true), escapeProperty(v.Value, false))
+		}
+	default:
+		return fmt.Errorf("unsupported format '%s'", format)
+	}
+
+	return nil
+}
+
+func envMap(vars []EnvVar) map[string]string {
+	m := map[string]string{}
+	for _, v := range vars {
+		m[v.Name] = v.Value
+	}
+	return m
+}
+
+var dotenvEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"$", `\$`,
+	"`", "\\`",
+	"\n", `\n`,
+	"\r", `\r`,
+)
+
+func escapeDotenv(value string) string {
+	return dotenvEscaper.Replace(value)
+}
+
+// escapeProperty escapes a key or value for a Java .properties file, which
+// is read as ISO-8859-1 and so needs anything else written as \uXXXX.
+func escapeProperty(s string, isKey bool) string {
+	out := strings.Builder{}
+
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			out.WriteString(`\\`)
+		case r == '\n':
+			out.WriteString(`\n`)
+		case r == '\r':
+			out.WriteString(`\r`)
+		case r == '\t':
+			out.WriteString(`\t`)
+		case r == '\f':
+			out.WriteString(`\f`)
+		case r == ' ' && (isKey || i == 0):
+			out.WriteString(`\ `)
+		case strings.ContainsRune("=:#!", r):
+			out.WriteRune('\\')
+			out.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			for _, unit := range utf16.Encode([]rune{r}) {
+				fmt.Fprintf(&out, `\u%04x`, unit)
+			}
+		default:
+			out.WriteRune(r)
+		}
+	}
+
+	return out.String()
+}
@@ -0,0 +1,76 @@
+package pstore
+
+import (
+	"bytes"
+	"testing"
+)
+
+var envValues = []string{
+	"",
+	"plain",
+	`back\slash`,
+	`"quoted"`,
+	"$HOME and ${PATH}",
+	"`whoami`",
+	"multi\nline\r\n",
+	"tab\there",
+	" leading and trailing ",
+	"# not a comment",
+	"key=value: x!",
+	"héllo 日本 😀",
+}
+
+func TestEscapeDotenv(t *testing.T) {
+	tests := map[string]string{
+		`a\b`:     `a\\b`,
+		`say "x"`: `say \"x\"`,
+		"$HOME":   `\$HOME`,
+		"`id`":    "\\`id\\`",
+		"a\nb\r":  `a\nb\r`,
+	}
+
+	for value, want := range tests {
+		if got := escapeDotenv(value); got != want {
+			t.Errorf("escapeDotenv(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestEscapeProperty(t *testing.T) {
+	tests := []struct {
+		value string
+		isKey bool
+		want  string
+	}{
+		{"a b", true, `a\ b`},
+		{" a b", false, `\ a b`},
+		{"a=b:c#d!e", false, `a\=b\:c\#d\!e`},
+		{"a\\b\n\t", false, `a\\b\n\t`},
+		{"é", false, `\u00e9`},
+		{"😀", false, `\ud83d\ude00`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeProperty(tt.value, tt.isKey); got != tt.want {
+			t.Errorf("escapeProperty(%q, %v) = %q, want %q", tt.value, tt.isKey, got, tt.want)
+		}
+	}
+}
+
+func TestDotenvRoundTrip(t *testing.T) {
+	for _, value := range envValues {
+		out := &bytes.Buffer{}
+		if err := WriteEnv(out, FormatDotenv, []EnvVar{{Name: "V", Value: value}}); err != nil {
+			t.Fatal(err)
+		}
+
+		parsed, err := ParseEnvFile(FormatDotenv, out.Bytes())
+		if err != nil {
+			t.Fatalf("%q: %v", out.String(), err)
+		}
+
+		if parsed["V"] != value {
+			t.Errorf("got %q back from %q, want %q", parsed["V"], out.String(), value)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package pstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/fatih/color"
+)
+
+const (
+	FormatSystemd  = "systemd"
+	FormatMakefile = "makefile"
+)
+
+// Formatter writes resolved parameters out as environment variables.
+type Formatter func(w io.Writer, params []ParamResult) error
+
+var formatters = map[string]Formatter{}
+
+// RegisterFormatter makes a formatter available by name.
+func RegisterFormatter(name string, formatter Formatter) {
+	formatters[name] = formatter
+}
+
+// LookupFormatter returns the formatter registered under name.
+func LookupFormatter(name string) (Formatter, error) {
+	if formatter, ok := formatters[name]; ok {
+		return formatter, nil
+	}
+	return nil, fmt.Errorf("unknown format '%s', expected one of %s", name, strings.Join(FormatterNames(), ", "))
+}
+
+// FormatterNames returns the names of the registered formatters, sorted.
+func FormatterNames() []string {
+	names := []string{}
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	for _, format := range []string{FormatDotenv, FormatJSON, FormatYAML, FormatDockerEnv, FormatProperties} {
+		RegisterFormatter(format, envFormatter(format))
+	}
+
+	for _, dialect := range []string{ShellSh, ShellBash, ShellZsh, ShellFish, ShellTcsh, ShellNu} {
+		dialect := dialect
+		RegisterFormatter(dialect, statementFormatter("", func(key, value string) (string, error) {
+			return ShellExport(dialect, key, value)
+		}))
+	}
+
+	RegisterFormatter(FormatPowershell, statementFormatter("", PowershellExport))
+	RegisterFormatter(FormatPs1, statementFormatter(Ps1Header(), PowershellExport))
+	RegisterFormatter(FormatCmd, statementFormatter("", CmdExport))
+	RegisterFormatter(FormatSystemd, statementFormatter("", systemdAssignment))
+	RegisterFormatter(FormatMakefile, statementFormatter("", makefileExport))
+}
+
+// sortedResults is params sorted by env var name.
+func sortedResults(params []ParamResult) []ParamResult {
+	params = append([]ParamResult{}, params...)
+	sort.SliceStable(params, func(i, j int) bool {
+		return params[i].EnvName < params[j].EnvName
+	})
+	return params
+}
+
+func envFormatter(format string) Formatter {
+	return func(w io.Writer, params []ParamResult) error {
+		vars := []EnvVar{}
+		for _, param := range params {
+			vars = append(vars, EnvVar{Name: param.EnvName, Value: param.Value})
+		}
+		return WriteEnv(w, format, vars)
+	}
+}
+
+// statementFormatter writes header, if any, followed by one statement per
+// parameter. Parameters whose names can't be env vars are skipped with a
+// warning on stderr, but otherwise nothing is written unless every statement
+// can be.
+func statementFormatter(header string, statement func(key, value string) (string, error)) Formatter {
+	return func(w io.Writer, params []ParamResult) error {
+		lines := []string{}
+		if header != "" {
+			lines = append(lines, header)
+		}
+
+		for _, param := range sortedResults(params) {
+			if !validEnvName.MatchString(param.EnvName) {
+				color.New(color.FgYellow).Fprintf(os.Stderr, "WARNING: skipping %s, %s isn't a valid environment variable name\n", param.ParamName, param.EnvName)
+				continue
+			}
+
+			line, err := statement(param.EnvName, param.Value)
+			if err != nil {
+				return err
+			}
+			lines = append(lines, line)
+		}
+
+		for _, line := range lines {
+			fmt.Fprintln(w, line)
+		}
+		return nil
+	}
+}
+
+// systemdAssignment is a line for a systemd EnvironmentFile. Inside double
+// quotes systemd takes newlines literally and only needs backslash, quote,
+// backtick and dollar escaped.
+func systemdAssignment(key, value string) (string, error) {
+	if !validEnvName.MatchString(key) {
+		return "", fmt.Errorf("%s isn't a valid environment variable name", key)
+	}
+
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", "$", `\$`).Replace(value)
+	return fmt.Sprintf(`%s="%s"`, key, escaped), nil
+}
+
+// makefileExport is a Makefile line exporting key as value. Make has no
+// quoting, so dollars are doubled, comment characters escaped and $() used
+// to keep whitespace at the start and a backslash at the end literal.
+func makefileExport(key, value string) (string, error) {
+	if !validEnvName.MatchString(key) {
+		return "", fmt.Errorf("%s isn't a valid environment variable name", key)
+	}
+
+	if strings.ContainsAny(value, "\r\n") {
+		return "", fmt.Errorf("%s has a newline, which a Makefile variable can't hold", key)
+	}
+
+	escaped := strings.Builder{}
+	backslashes := 0
+	for _, r := range value {
+		switch r {
+		case '\\':
+			backslashes++
+			escaped.WriteRune(r)
+			continue
+		case '#':
+			// backslashes before an escaped # have to be escaped too
+			escaped.WriteString(strings.Repeat(`\`, backslashes) + `\#`)
+		case '$':
+			escaped.WriteString("$$")
+		default:
+			escaped.WriteRune(r)
+		}
+		backslashes = 0
+	}
+
+	result := escaped.String()
+	if strings.HasPrefix(result, " ") || strings.HasPrefix(result, "\t") {
+		result = "$()" + result
+	}
+	if strings.HasSuffix(result, `\`) {
+		result += "$()"
+	}
+
+	return fmt.Sprintf("export %s := %s", key, result), nil
+}
+
+// TemplateParam is what a custom template is executed with, once per
+// parameter.
+type TemplateParam struct {
+	Key     string // the env var name
+	Value   string
+	Name    string // the parameter name
+	Source  string // name, path or tag
+	Version int64
+	Type    string
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(value string) string {
+		bytes, _ := json.Marshal(value)
+		return string(bytes)
+	},
+	"shquote": quotePosix,
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+}
+
+// TemplateFormatter parses text as a Go template that's executed for each
+// parameter, with a newline after each. Besides the usual functions, json,
+// shquote, upper and lower are available for quoting values.
+func TemplateFormatter(text string) (Formatter, error) {
+	tmpl, err := template.New("format").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(w io.Writer, params []ParamResult) error {
+		out := strings.Builder{}
+
+		for _, param := range sortedResults(params) {
+			err := tmpl.Execute(&out, TemplateParam{
+				Key:     param.EnvName,
+				Value:   param.Value,
+				Name:    param.ParamName,
+				Source:  param.Source,
+				Version: param.Version,
+				Type:    param.Type,
+			})
+			if err != nil {
+				return err
+			}
+			out.WriteString("\n")
+		}
+
+		_, err := io.WriteString(w, out.String())
+		return err
+	}, nil
+}
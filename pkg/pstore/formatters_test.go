@@ -0,0 +1,45 @@
+package pstore
+
+import (
+	"strings"
+	"testing"
+)
+
+var unixValues = append(append([]ParamResult{}, windowsValues...),
+	ParamResult{EnvName: "BACKSLASH", Value: `C:\path\`},
+	ParamResult{EnvName: "BACKSLASH_HASH", Value: `a\#b`},
+	ParamResult{EnvName: "HASH", Value: "# not a comment"},
+	ParamResult{EnvName: "DOLLAR", Value: "$HOME $(id) $$"},
+	ParamResult{EnvName: "LEADING_SPACE", Value: "  indented"},
+)
+
+func TestSystemdGolden(t *testing.T) {
+	params := append(unixValues, ParamResult{EnvName: "MULTILINE", Value: "line 1\nline 2"})
+	testGolden(t, FormatSystemd, "env.systemd.golden", params)
+}
+
+func TestMakefileGolden(t *testing.T) {
+	testGolden(t, FormatMakefile, "env.makefile.golden", unixValues)
+}
+
+func TestTemplateFormatter(t *testing.T) {
+	formatter, err := TemplateFormatter(`{{.Key}} {{.Source}} v{{.Version}} {{json .Value}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := []ParamResult{
+		{EnvName: "B", Value: `say "hi"`, Source: SourceTag, Version: 3},
+		{EnvName: "A", Value: "x", Source: SourceName, Version: 12},
+	}
+
+	out := &strings.Builder{}
+	if err := formatter(out, params); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "A name v12 \"x\"\nB tag v3 \"say \\\"hi\\\"\"\n"
+	if out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,88 @@
+package pstore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// Selector is name qualified by a version or label, in the name:selector
+// form GetParameter accepts. At most one of version and label may be set.
+func Selector(name string, version int64, label string) (string, error) {
+	if version != 0 && label != "" {
+		return "", fmt.Errorf("a version and a label can't both be selected")
+	}
+
+	if _, selector := splitSelector(name); selector != "" && (version != 0 || label != "") {
+		return "", fmt.Errorf("%s already has a selector", name)
+	}
+
+	switch {
+	case version != 0:
+		return fmt.Sprintf("%s:%d", name, version), nil
+	case label != "":
+		return name + ":" + label, nil
+	default:
+		return name, nil
+	}
+}
+
+// splitSelector splits a name or ARN from the version or label selector
+// that may follow it, which is empty if there isn't one.
+func splitSelector(ref string) (name, selector string) {
+	// ARNs have colons too, but only selectors follow the last slash
+	slash := strings.LastIndex(ref, "/")
+	colon := strings.LastIndex(ref, ":")
+	if colon <= slash {
+		return ref, ""
+	}
+	return ref[:colon], ref[colon+1:]
+}
+
+// GetParameter returns a single parameter, which can be qualified by a
+// version or label selector.
+func GetParameter(sess *session.Session, name string, decrypt bool) (*ssm.Parameter, error) {
+	api := ssm.New(sess)
+
+	resp, err := api.GetParameter(&ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(decrypt),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Parameter, nil
+}
+
+// ExitStatus is the exit status that tells scripts why reading a parameter
+// failed.
+func ExitStatus(err error) int {
+	aerr, ok := err.(awserr.Error)
+	if !ok {
+		return PstoreError
+	}
+
+	switch aerr.Code() {
+	case ssm.ErrCodeParameterNotFound, ssm.ErrCodeParameterVersionNotFound:
+		return NotFoundError
+	case ssm.ErrCodeInvalidKeyId:
+		return DecryptError
+	case "AccessDeniedException":
+		// SSM reports a missing kms:Decrypt permission as access denied too
+		if strings.Contains(strings.ToLower(aerr.Message()), "kms") {
+			return DecryptError
+		}
+		return AccessDeniedError
+	}
+
+	if strings.HasPrefix(aerr.Code(), "KMS") {
+		return DecryptError
+	}
+
+	return PstoreError
+}
@@ -0,0 +1,61 @@
+package pstore
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// GetParameterHistory returns every version of a parameter, oldest first.
+func GetParameterHistory(sess *session.Session, name string, decrypt bool) ([]*ssm.ParameterHistory, error) {
+	api := ssm.New(sess)
+	history := []*ssm.ParameterHistory{}
+
+	err := api.GetParameterHistoryPages(&ssm.GetParameterHistoryInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(decrypt),
+	}, func(page *ssm.GetParameterHistoryOutput, lastPage bool) bool {
+		history = append(history, page.Parameters...)
+		return !lastPage
+	})
+
+	return history, err
+}
+
+// Rollback writes an old version of a parameter back as a new version,
+// with the type, KMS key, description and allowed pattern it had then. It
+// returns the new version.
+func Rollback(sess *session.Session, name string, version int64) (int64, error) {
+	history, err := GetParameterHistory(sess, name, true)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, old := range history {
+		if *old.Version != version {
+			continue
+		}
+
+		put := PutRequest{
+			Name:           name,
+			Value:          *old.Value,
+			Type:           *old.Type,
+			KeyID:          aws.StringValue(old.KeyId),
+			Description:    aws.StringValue(old.Description),
+			AllowedPattern: aws.StringValue(old.AllowedPattern),
+			Overwrite:      true,
+		}
+
+		// parameters can't go back to the standard tier once advanced, so
+		// the tier is only carried over when it's an upgrade
+		if aws.StringValue(old.Tier) == ssm.ParameterTierAdvanced {
+			put.Tier = ssm.ParameterTierAdvanced
+		}
+
+		return PutParameter(sess, put)
+	}
+
+	return 0, fmt.Errorf("%s has no version %d", name, version)
+}
@@ -0,0 +1,250 @@
+package pstore
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// the most values a single DescribeParameters filter accepts
+const describeFilterValuesLimit = 50
+
+type PolicyDocument struct {
+	Version   string
+	Statement []PolicyStatement
+}
+
+type PolicyStatement struct {
+	Sid       string
+	Effect    string
+	Action    []string
+	Resource  []string
+	Condition map[string]map[string]string `json:",omitempty"`
+}
+
+// ParameterARN builds the ARN of a parameter, which has a slash between
+// "parameter" and the name whether or not the name starts with one.
+func ParameterARN(partition, region, account, name string) string {
+	return arn.ARN{
+		Partition: partition,
+		Service:   "ssm",
+		Region:    region,
+		AccountID: account,
+		Resource:  "parameter/" + strings.TrimPrefix(name, "/"),
+	}.String()
+}
+
+// referenceARN is the ARN of the parameter ref refers to, which is ref
+// itself (less any selector) when it's already an ARN.
+func referenceARN(partition, region, account, ref string) string {
+	name, _ := splitSelector(ref)
+	if arn.IsARN(name) {
+		return name
+	}
+	return ParameterARN(partition, region, account, name)
+}
+
+// parameterName is the name of the parameter ref refers to, without any
+// selector and with an ARN turned back into a name. Names with a slash in
+// them always start with one, so only those get it back.
+func parameterName(ref string) string {
+	name, _ := splitSelector(ref)
+	parsed, err := arn.Parse(name)
+	if err != nil {
+		return name
+	}
+
+	name = strings.TrimPrefix(parsed.Resource, "parameter/")
+	if strings.Contains(name, "/") {
+		name = "/" + name
+	}
+	return name
+}
+
+// Policy generates a least-privilege IAM policy granting exactly the access
+// pstore needs to resolve req: the named parameters, the paths, parameters
+// carrying the tags and the KMS keys that encrypt them.
+func Policy(sess *session.Session, req ParamsRequest) (PolicyDocument, error) {
+	doc := PolicyDocument{Version: "2012-10-17", Statement: []PolicyStatement{}}
+
+	identity, err := sts.New(sess).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return doc, err
+	}
+
+	caller, err := arn.Parse(*identity.Arn)
+	if err != nil {
+		return doc, err
+	}
+
+	region := *sess.Config.Region
+	paramARN := func(ref string) string {
+		return referenceARN(caller.Partition, region, *identity.Account, ref)
+	}
+
+	if len(req.SimpleParams) > 0 {
+		resources := []string{}
+		for _, name := range req.SimpleParams {
+			resources = append(resources, paramARN(name))
+		}
+
+		doc.Statement = append(doc.Statement, PolicyStatement{
+			Sid:      "ReadNamedParameters",
+			Effect:   "Allow",
+			Action:   []string{"ssm:GetParameter", "ssm:GetParameters"},
+			Resource: sortedUnique(resources),
+		})
+	}
+
+	if len(req.PathParams) > 0 {
+		resources := []string{}
+		for _, path := range req.PathParams {
+			path = strings.TrimSuffix(path, "/")
+			resources = append(resources, paramARN(path), paramARN(path+"/*"))
+		}
+
+		doc.Statement = append(doc.Statement, PolicyStatement{
+			Sid:      "ReadParameterPaths",
+			Effect:   "Allow",
+			Action:   []string{"ssm:GetParametersByPath"},
+			Resource: sortedUnique(resources),
+		})
+	}
+
+	if len(req.TaggedParams) > 0 {
+		doc.Statement = append(doc.Statement, PolicyStatement{
+			Sid:      "FindTaggedParameters",
+			Effect:   "Allow",
+			Action:   []string{"tag:GetResources"},
+			Resource: []string{"*"},
+		})
+
+		keys := []string{}
+		for key := range req.TaggedParams {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for idx, key := range keys {
+			doc.Statement = append(doc.Statement, PolicyStatement{
+				Sid:      fmt.Sprintf("ReadTaggedParameters%d", idx+1),
+				Effect:   "Allow",
+				Action:   []string{"ssm:GetParameters"},
+				Resource: []string{paramARN("*")},
+				Condition: map[string]map[string]string{
+					"StringEquals": {"ssm:resourceTag/" + key: req.TaggedParams[key]},
+				},
+			})
+		}
+	}
+
+	keyARNs, err := secureStringKeys(sess, req)
+	if err != nil {
+		return doc, err
+	}
+
+	if len(keyARNs) > 0 {
+		doc.Statement = append(doc.Statement, PolicyStatement{
+			Sid:      "DecryptSecureStrings",
+			Effect:   "Allow",
+			Action:   []string{"kms:Decrypt"},
+			Resource: keyARNs,
+			Condition: map[string]map[string]string{
+				"StringEquals": {"kms:ViaService": fmt.Sprintf("ssm.%s.amazonaws.com", region)},
+			},
+		})
+	}
+
+	return doc, nil
+}
+
+// secureStringKeys finds the ARNs of the KMS keys used by SecureStrings that
+// req refers to.
+func secureStringKeys(sess *session.Session, req ParamsRequest) ([]string, error) {
+	filters := [][]*ssm.ParameterStringFilter{}
+
+	names := []string{}
+	for _, ref := range req.SimpleParams {
+		names = append(names, parameterName(ref))
+	}
+
+	for start := 0; start < len(names); start += describeFilterValuesLimit {
+		end := start + describeFilterValuesLimit
+		if end > len(names) {
+			end = len(names)
+		}
+
+		filters = append(filters, []*ssm.ParameterStringFilter{
+			{Key: aws.String("Name"), Option: aws.String("Equals"), Values: aws.StringSlice(names[start:end])},
+		})
+	}
+
+	for _, path := range req.PathParams {
+		filters = append(filters, []*ssm.ParameterStringFilter{
+			{Key: aws.String("Path"), Option: aws.String("Recursive"), Values: aws.StringSlice([]string{path})},
+		})
+	}
+
+	for key, value := range req.TaggedParams {
+		filters = append(filters, []*ssm.ParameterStringFilter{
+			{Key: aws.String("tag:" + key), Values: aws.StringSlice([]string{value})},
+		})
+	}
+
+	api := ssm.New(sess)
+	keyIds := map[string]bool{}
+
+	for _, filter := range filters {
+		filter = append(filter, &ssm.ParameterStringFilter{
+			Key:    aws.String("Type"),
+			Values: aws.StringSlice([]string{ssm.ParameterTypeSecureString}),
+		})
+
+		err := api.DescribeParametersPages(&ssm.DescribeParametersInput{ParameterFilters: filter}, func(page *ssm.DescribeParametersOutput, lastPage bool) bool {
+			for _, param := range page.Parameters {
+				if param.KeyId != nil {
+					keyIds[*param.KeyId] = true
+				}
+			}
+			return !lastPage
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	keys := kms.New(sess)
+	keyARNs := []string{}
+
+	for keyId := range keyIds {
+		resp, err := keys.DescribeKey(&kms.DescribeKeyInput{KeyId: aws.String(keyId)})
+		if err != nil {
+			return nil, err
+		}
+		keyARNs = append(keyARNs, *resp.KeyMetadata.Arn)
+	}
+
+	return sortedUnique(keyARNs), nil
+}
+
+func sortedUnique(values []string) []string {
+	seen := map[string]bool{}
+	unique := []string{}
+
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+
+	sort.Strings(unique)
+	return unique
+}
@@ -0,0 +1,37 @@
+package pstore
+
+import "testing"
+
+const testARN = "arn:aws:ssm:us-east-1:123456789012:parameter"
+
+func TestReferenceARN(t *testing.T) {
+	tests := map[string]string{
+		"MyParam":             testARN + "/MyParam",
+		"MyParam:3":           testARN + "/MyParam",
+		"/app/prod/db:live":   testARN + "/app/prod/db",
+		testARN + "/app/db":   testARN + "/app/db",
+		testARN + "/app/db:2": testARN + "/app/db",
+		"arn:aws:ssm:eu-west-1:999999999999:parameter/shared": "arn:aws:ssm:eu-west-1:999999999999:parameter/shared",
+	}
+
+	for ref, want := range tests {
+		if got := referenceARN("aws", "us-east-1", "123456789012", ref); got != want {
+			t.Errorf("referenceARN(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func TestParameterName(t *testing.T) {
+	tests := map[string]string{
+		"MyParam:3":           "MyParam",
+		"/app/db:live":        "/app/db",
+		testARN + "/MyParam":  "MyParam",
+		testARN + "/app/db:2": "/app/db",
+	}
+
+	for ref, want := range tests {
+		if got := parameterName(ref); got != want {
+			t.Errorf("parameterName(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
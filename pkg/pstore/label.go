@@ -0,0 +1,123 @@
+package pstore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// LabelParameter attaches labels to a version of a parameter, or to the
+// latest version if version is zero. It returns the version labelled.
+// Labels move if another version already has them.
+func LabelParameter(sess *session.Session, name string, version int64, labels []string) (int64, error) {
+	input := &ssm.LabelParameterVersionInput{
+		Name:   aws.String(name),
+		Labels: aws.StringSlice(labels),
+	}
+	if version > 0 {
+		input.ParameterVersion = aws.Int64(version)
+	}
+
+	resp, err := ssm.New(sess).LabelParameterVersion(input)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(resp.InvalidLabels) > 0 {
+		return 0, fmt.Errorf("invalid labels: %s", strings.Join(aws.StringValueSlice(resp.InvalidLabels), ", "))
+	}
+
+	return aws.Int64Value(resp.ParameterVersion), nil
+}
+
+// UnlabelParameter removes labels from a parameter. When version is zero,
+// they're removed from whichever versions they're currently on.
+func UnlabelParameter(sess *session.Session, name string, version int64, labels []string) error {
+	byVersion := map[int64][]string{version: labels}
+
+	if version == 0 {
+		var err error
+		byVersion, err = labelledVersions(sess, name, labels)
+		if err != nil {
+			return err
+		}
+	}
+
+	api := ssm.New(sess)
+	removed := map[string]bool{}
+
+	for version, labels := range byVersion {
+		resp, err := api.UnlabelParameterVersion(&ssm.UnlabelParameterVersionInput{
+			Name:             aws.String(name),
+			ParameterVersion: aws.Int64(version),
+			Labels:           aws.StringSlice(labels),
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, label := range resp.RemovedLabels {
+			removed[*label] = true
+		}
+	}
+
+	missing := []string{}
+	for _, label := range labels {
+		if !removed[label] {
+			missing = append(missing, label)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%s has no labels %s", name, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// labelledVersions finds which versions of a parameter carry labels.
+func labelledVersions(sess *session.Session, name string, labels []string) (map[int64][]string, error) {
+	history, err := GetParameterHistory(sess, name, false)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := map[string]bool{}
+	for _, label := range labels {
+		wanted[label] = true
+	}
+
+	byVersion := map[int64][]string{}
+	for _, version := range history {
+		for _, label := range version.Labels {
+			if wanted[*label] {
+				byVersion[*version.Version] = append(byVersion[*version.Version], *label)
+			}
+		}
+	}
+
+	return byVersion, nil
+}
+
+// LabelPath puts label on the current version of every parameter under
+// path, calling done after each one. The versions are all read before any
+// are labelled, so that together they mark a consistent release.
+func LabelPath(sess *session.Session, path, label string, done func(name string, version int64, err error)) error {
+	params, err := GetParameterTree(sess, path, true, false)
+	if err != nil {
+		return err
+	}
+
+	for _, param := range params {
+		version, err := LabelParameter(sess, *param.Name, *param.Version, []string{label})
+		if err != nil {
+			version = *param.Version
+		}
+		done(*param.Name, version, err)
+	}
+
+	return nil
+}
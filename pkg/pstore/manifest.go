@@ -0,0 +1,51 @@
+package pstore
+
+import (
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Manifest is a file-based alternative to describing references with
+// PSTORE_ env vars, e.g.
+//
+//	names:
+//	  DBSTRING: MyDatabaseString
+//	paths:
+//	  - /app/prod
+//	tags:
+//	  env: prod
+//
+// JSON manifests work too, being valid YAML.
+type Manifest struct {
+	Names map[string]string `yaml:"names"`
+	Paths []string          `yaml:"paths"`
+	Tags  map[string]string `yaml:"tags"`
+}
+
+func ReadManifest(path string) (ParamsRequest, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ParamsRequest{}, err
+	}
+
+	manifest := Manifest{}
+	if err := yaml.UnmarshalStrict(bytes, &manifest); err != nil {
+		return ParamsRequest{}, err
+	}
+
+	req := ParamsRequest{
+		SimpleParams: manifest.Names,
+		TaggedParams: manifest.Tags,
+		PathParams:   manifest.Paths,
+	}
+
+	if req.SimpleParams == nil {
+		req.SimpleParams = map[string]string{}
+	}
+	if req.TaggedParams == nil {
+		req.TaggedParams = map[string]string{}
+	}
+
+	return req, nil
+}
@@ -0,0 +1,328 @@
+package pstore
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// GetParameters accepts at most this many names per call
+const getParametersBatchSize = 10
+
+var validEnvName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// PlannedParam is an env var that resolving a ParamsRequest would set, as
+// far as can be determined without decrypting anything.
+type PlannedParam struct {
+	EnvName   string
+	ParamName string
+	Source    string
+	Reference string // the value of the env var that asked for it
+	Exists    bool
+	Type      string
+	Version   int64
+	Problems  []string
+}
+
+func (p PlannedParam) OK() bool {
+	return p.Exists && len(p.Problems) == 0
+}
+
+// Plan expands req into the env vars it would produce without decrypting
+// any values, flagging missing parameters, invalid env var names and
+// collisions between references.
+func Plan(sess *session.Session, req ParamsRequest) ([]PlannedParam, error) {
+	planned := []PlannedParam{}
+
+	for envName, paramName := range req.SimpleParams {
+		planned = append(planned, PlannedParam{
+			EnvName:   envName,
+			ParamName: paramName,
+			Source:    SourceName,
+			Reference: paramName,
+		})
+	}
+
+	for _, path := range req.PathParams {
+		params, err := planPath(sess, path)
+		if err != nil {
+			return nil, err
+		}
+		planned = append(planned, params...)
+	}
+
+	for key, value := range req.TaggedParams {
+		params, err := planTag(sess, key, value)
+		if err != nil {
+			return nil, err
+		}
+		planned = append(planned, params...)
+	}
+
+	if err := describePlanned(sess, planned); err != nil {
+		return nil, err
+	}
+
+	checkPlanned(planned)
+
+	sort.SliceStable(planned, func(i, j int) bool {
+		return planned[i].EnvName < planned[j].EnvName
+	})
+
+	return planned, nil
+}
+
+func planPath(sess *session.Session, path string) ([]PlannedParam, error) {
+	api := ssm.New(sess)
+	planned := []PlannedParam{}
+
+	err := api.GetParametersByPathPages(&ssm.GetParametersByPathInput{
+		Path:           &path,
+		Recursive:      aws.Bool(true),
+		WithDecryption: aws.Bool(false),
+	}, func(page *ssm.GetParametersByPathOutput, lastPage bool) bool {
+		for _, param := range page.Parameters {
+			planned = append(planned, PlannedParam{
+				EnvName:   PathEnvName(*param.Name),
+				ParamName: *param.Name,
+				Source:    SourcePath,
+				Reference: path,
+				Exists:    true,
+				Type:      *param.Type,
+				Version:   *param.Version,
+			})
+		}
+		return !lastPage
+	})
+
+	if err == nil && len(planned) == 0 {
+		planned = append(planned, PlannedParam{
+			Source:    SourcePath,
+			Reference: path,
+			Problems:  []string{"no parameters under path"},
+		})
+	}
+
+	return planned, err
+}
+
+func planTag(sess *session.Session, key, value string) ([]PlannedParam, error) {
+	api := resourcegroupstaggingapi.New(sess)
+	planned := []PlannedParam{}
+	reference := key + "=" + value
+
+	err := api.GetResourcesPages(&resourcegroupstaggingapi.GetResourcesInput{
+		TagFilters: []*resourcegroupstaggingapi.TagFilter{
+			{Key: &key, Values: aws.StringSlice([]string{value})},
+		},
+		ResourceTypeFilters: aws.StringSlice([]string{"ssm:parameter"}),
+	}, func(page *resourcegroupstaggingapi.GetResourcesOutput, lastPage bool) bool {
+		for _, r := range page.ResourceTagMappingList {
+			param := PlannedParam{
+				ParamName: strings.SplitN(*r.ResourceARN, "parameter", 2)[1],
+				Source:    SourceTag,
+				Reference: reference,
+			}
+
+			if envName := TagValueWithKey(r.Tags, EnvNameTag); envName != nil {
+				param.EnvName = *envName
+			} else {
+				param.Problems = append(param.Problems, fmt.Sprintf("no %s tag, will be skipped", EnvNameTag))
+			}
+
+			planned = append(planned, param)
+		}
+		return !lastPage
+	})
+
+	if err == nil && len(planned) == 0 {
+		planned = append(planned, PlannedParam{
+			Source:    SourceTag,
+			Reference: reference,
+			Problems:  []string{"no parameters with tag"},
+		})
+	}
+
+	return planned, err
+}
+
+// describePlanned fills in the type and version of named and tagged
+// parameters, which don't come back from the calls that found them.
+func describePlanned(sess *session.Session, planned []PlannedParam) error {
+	api := ssm.New(sess)
+	byRef := map[string][]int{}
+	refs := []string{}
+
+	for idx, param := range planned {
+		if param.Source == SourcePath || param.ParamName == "" {
+			continue
+		}
+		if _, seen := byRef[param.ParamName]; !seen {
+			refs = append(refs, param.ParamName)
+		}
+		byRef[param.ParamName] = append(byRef[param.ParamName], idx)
+	}
+
+	// references can be names or ARNs with a selector, but what comes back
+	// is the parameter's own name and ARN with the selector on its own
+	bySelected := map[string][]int{}
+	for ref, indices := range byRef {
+		name, selector := splitSelector(ref)
+		bySelected[name+":"+selector] = indices
+	}
+
+	invalid := map[int]bool{}
+	describe := func(refs []string) error {
+		resp, err := api.GetParameters(&ssm.GetParametersInput{
+			Names:          aws.StringSlice(refs),
+			WithDecryption: aws.Bool(false),
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, p := range resp.Parameters {
+			selector := strings.TrimPrefix(aws.StringValue(p.Selector), ":")
+			indices := bySelected[*p.Name+":"+selector]
+			if *p.ARN != *p.Name {
+				indices = append(indices, bySelected[*p.ARN+":"+selector]...)
+			}
+
+			for _, idx := range indices {
+				planned[idx].Exists = true
+				planned[idx].Type = *p.Type
+				planned[idx].Version = *p.Version
+			}
+		}
+		return nil
+	}
+
+	for start := 0; start < len(refs); start += getParametersBatchSize {
+		end := start + getParametersBatchSize
+		if end > len(refs) {
+			end = len(refs)
+		}
+
+		err := describe(refs[start:end])
+		if !isValidationError(err) {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		// one malformed reference fails the whole batch, so find which
+		for _, ref := range refs[start:end] {
+			err := describe([]string{ref})
+			if isValidationError(err) {
+				for _, idx := range byRef[ref] {
+					invalid[idx] = true
+					planned[idx].Problems = append(planned[idx].Problems, "invalid reference: "+err.(awserr.Error).Message())
+				}
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for idx := range planned {
+		if !planned[idx].Exists && planned[idx].ParamName != "" && !invalid[idx] {
+			planned[idx].Problems = append(planned[idx].Problems, "parameter not found")
+		}
+	}
+
+	return nil
+}
+
+func isValidationError(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == "ValidationException"
+}
+
+func checkPlanned(planned []PlannedParam) {
+	byEnvName := map[string][]int{}
+
+	for idx, param := range planned {
+		if param.EnvName == "" {
+			continue
+		}
+		if !validEnvName.MatchString(param.EnvName) {
+			planned[idx].Problems = append(planned[idx].Problems, "invalid env var name")
+		}
+		byEnvName[param.EnvName] = append(byEnvName[param.EnvName], idx)
+	}
+
+	for envName, indices := range byEnvName {
+		if len(indices) < 2 {
+			continue
+		}
+		for _, idx := range indices {
+			problem := fmt.Sprintf("%s is set by %d references", envName, len(indices))
+			planned[idx].Problems = append(planned[idx].Problems, problem)
+		}
+	}
+}
+
+// EnvNames returns the names of the env vars that resolving the request in
+// the environment would set, without decrypting anything. Names given by the
+// simple prefix are known without asking AWS. Path and tag references are
+// expanded the same way Plan does, and any that can't be are passed to warn
+// rather than stopping the rest from being returned. Invalid names are left
+// out.
+func EnvNames(opts Options, warn func(error)) []string {
+	req := GetParamRequestFromEnv(opts.SimplePrefix, opts.TagPrefix, opts.PathPrefix)
+
+	names := []string{}
+	for envName := range req.SimpleParams {
+		if validEnvName.MatchString(envName) {
+			names = append(names, envName)
+		}
+	}
+
+	if len(req.PathParams) == 0 && len(req.TaggedParams) == 0 {
+		return sortedUnique(names)
+	}
+
+	region := awsRegion()
+	if region == "" {
+		warn(fmt.Errorf("no AWS region specified, so path and tag references can't be expanded"))
+		return sortedUnique(names)
+	}
+
+	sess, _ := session.NewSession(aws.NewConfig().WithRegion(region))
+	sess.Handlers.Build.PushBackNamed(userAgentHandler)
+
+	planned := []PlannedParam{}
+	for _, path := range req.PathParams {
+		params, err := planPath(sess, path)
+		if err != nil {
+			warn(fmt.Errorf("%s: %s", path, err))
+		}
+		planned = append(planned, params...)
+	}
+
+	for key, value := range req.TaggedParams {
+		params, err := planTag(sess, key, value)
+		if err != nil {
+			warn(fmt.Errorf("%s=%s: %s", key, value, err))
+		}
+		planned = append(planned, params...)
+	}
+
+	for _, param := range planned {
+		if validEnvName.MatchString(param.EnvName) {
+			names = append(names, param.EnvName)
+		}
+	}
+
+	return sortedUnique(names)
+}
@@ -0,0 +1,111 @@
+package pstore
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+func TestSplitSelector(t *testing.T) {
+	tests := []struct{ ref, name, selector string }{
+		{"MyParam", "MyParam", ""},
+		{"MyParam:3", "MyParam", "3"},
+		{"/app/prod/db:live", "/app/prod/db", "live"},
+		{"arn:aws:ssm:us-east-1:123456789012:parameter/app/db", "arn:aws:ssm:us-east-1:123456789012:parameter/app/db", ""},
+		{"arn:aws:ssm:us-east-1:123456789012:parameter/app/db:2", "arn:aws:ssm:us-east-1:123456789012:parameter/app/db", "2"},
+	}
+
+	for _, tt := range tests {
+		name, selector := splitSelector(tt.ref)
+		if name != tt.name || selector != tt.selector {
+			t.Errorf("splitSelector(%q) = %q, %q, want %q, %q", tt.ref, name, selector, tt.name, tt.selector)
+		}
+	}
+}
+
+// fakeSSM answers GetParameters the way SSM does: a selector comes back on
+// its own, and a malformed name fails the whole call.
+func fakeSSM(t *testing.T) *session.Session {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		input := struct{ Names []string }{}
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			t.Error(err)
+			return
+		}
+
+		params := []map[string]interface{}{}
+		for _, ref := range input.Names {
+			if strings.Contains(ref, " ") {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"__type": "ValidationException", "message": "bad name " + ref})
+				return
+			}
+
+			name, selector := splitSelector(ref)
+			name = strings.TrimPrefix(name, "arn:aws:ssm:us-east-1:123456789012:parameter")
+			if name == "/missing" {
+				continue
+			}
+
+			param := map[string]interface{}{
+				"Name":    name,
+				"ARN":     "arn:aws:ssm:us-east-1:123456789012:parameter" + name,
+				"Type":    "SecureString",
+				"Version": 7,
+			}
+			if selector != "" {
+				param["Selector"] = ":" + selector
+			}
+			params = append(params, param)
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"Parameters": params, "InvalidParameters": []string{}})
+	}))
+	t.Cleanup(server.Close)
+
+	return session.Must(session.NewSession(aws.NewConfig().
+		WithRegion("us-east-1").
+		WithEndpoint(server.URL).
+		WithCredentials(credentials.NewStaticCredentials("AKID", "SECRET", "")).
+		WithMaxRetries(0)))
+}
+
+func TestDescribePlanned(t *testing.T) {
+	refs := []string{
+		"/app/db",
+		"/app/db:3",
+		"arn:aws:ssm:us-east-1:123456789012:parameter/app/key",
+		"arn:aws:ssm:us-east-1:123456789012:parameter/app/key:live",
+		"/missing",
+		"/not valid",
+	}
+
+	planned := []PlannedParam{}
+	for _, ref := range refs {
+		planned = append(planned, PlannedParam{EnvName: "V", ParamName: ref, Source: SourceName})
+	}
+
+	if err := describePlanned(fakeSSM(t), planned); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, param := range planned[:4] {
+		if !param.Exists || param.Version != 7 || len(param.Problems) > 0 {
+			t.Errorf("%s: got %+v, want it found", param.ParamName, param)
+		}
+	}
+
+	if problems := planned[4].Problems; len(problems) != 1 || problems[0] != "parameter not found" {
+		t.Errorf("/missing: got problems %q", problems)
+	}
+
+	if problems := planned[5].Problems; len(problems) != 1 || !strings.HasPrefix(problems[0], "invalid reference") {
+		t.Errorf("/not valid: got problems %q", problems)
+	}
+}
@@ -0,0 +1,90 @@
+package pstore
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// the tag tag mode looks for to name the env var a parameter is exposed as
+const EnvNameTag = "pstore:name"
+
+type PutRequest struct {
+	Name           string
+	Value          string
+	Type           string
+	KeyID          string
+	Description    string
+	AllowedPattern string
+	Tier           string
+	Overwrite      bool
+	Tags           map[string]string
+}
+
+// TagError is returned by PutParameter when the value was written but
+// tagging the parameter afterwards failed.
+type TagError struct {
+	Name    string
+	Version int64
+	Err     error
+}
+
+func (e *TagError) Error() string {
+	return fmt.Sprintf("%s was written as version %d, only tagging it failed: %s", e.Name, e.Version, e.Err)
+}
+
+// PutParameter creates or (if req.Overwrite is set) updates a parameter and
+// returns its new version.
+func PutParameter(sess *session.Session, req PutRequest) (int64, error) {
+	api := ssm.New(sess)
+
+	input := &ssm.PutParameterInput{
+		Name:      aws.String(req.Name),
+		Value:     aws.String(req.Value),
+		Type:      aws.String(req.Type),
+		Overwrite: aws.Bool(req.Overwrite),
+	}
+
+	if req.KeyID != "" {
+		input.KeyId = aws.String(req.KeyID)
+	}
+	if req.Description != "" {
+		input.Description = aws.String(req.Description)
+	}
+	if req.AllowedPattern != "" {
+		input.AllowedPattern = aws.String(req.AllowedPattern)
+	}
+	if req.Tier != "" {
+		input.Tier = aws.String(req.Tier)
+	}
+
+	tags := []*ssm.Tag{}
+	for key, value := range req.Tags {
+		tags = append(tags, &ssm.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+
+	// SSM refuses tags alongside overwrite, so they're added separately
+	if len(tags) > 0 && !req.Overwrite {
+		input.Tags = tags
+	}
+
+	resp, err := api.PutParameter(input)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(tags) > 0 && req.Overwrite {
+		_, err = api.AddTagsToResource(&ssm.AddTagsToResourceInput{
+			ResourceType: aws.String(ssm.ResourceTypeForTaggingParameter),
+			ResourceId:   aws.String(req.Name),
+			Tags:         tags,
+		})
+		if err != nil {
+			return *resp.Version, &TagError{Name: req.Name, Version: *resp.Version, Err: err}
+		}
+	}
+
+	return *resp.Version, nil
+}
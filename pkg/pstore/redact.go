@@ -0,0 +1,135 @@
+package pstore
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+const redactedValue = "***"
+
+// values shorter than this are left alone, masking them would mangle
+// ordinary output far more often than it would catch a leaked secret
+const minRedactLength = 4
+
+// Redactor is an io.Writer that replaces every occurrence of a secret value
+// with *** before passing output on. A secret split across two writes is
+// still caught: trailing bytes that could be the start of a secret are held
+// back until the next write (or Flush) shows whether they are.
+type Redactor struct {
+	mu      sync.Mutex
+	out     io.Writer
+	secrets [][]byte // longest first, so the longest match wins
+	first   [256]bool
+	pending []byte
+	scratch []byte
+}
+
+func NewRedactor(out io.Writer) *Redactor {
+	return &Redactor{out: out}
+}
+
+func (r *Redactor) SetSecrets(secrets []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.secrets = nil
+	r.first = [256]bool{}
+
+	for _, secret := range secrets {
+		if len(secret) < minRedactLength {
+			continue
+		}
+		r.secrets = append(r.secrets, []byte(secret))
+		r.first[secret[0]] = true
+	}
+
+	sort.Slice(r.secrets, func(i, j int) bool {
+		return len(r.secrets[i]) > len(r.secrets[j])
+	})
+}
+
+func (r *Redactor) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.redact(append(r.pending, p...), false); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Flush writes out anything held back as a possible partial secret. It is
+// called once the stream has ended, at which point it can't be one, though
+// it can still hold a shorter secret that was waiting to see whether a
+// longer one matched.
+func (r *Redactor) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.redact(r.pending, true)
+}
+
+// redact writes buf with secrets replaced. Unless the stream has ended,
+// trailing bytes that could be the start of a secret are kept in pending.
+func (r *Redactor) redact(buf []byte, final bool) error {
+	out := r.scratch[:0]
+	start, i := 0, 0
+
+scan:
+	for i < len(buf) {
+		if !r.first[buf[i]] {
+			i++
+			continue
+		}
+
+		rest := buf[i:]
+		for _, secret := range r.secrets {
+			if len(rest) < len(secret) {
+				if !final && bytes.HasPrefix(secret, rest) {
+					break scan
+				}
+				continue
+			}
+
+			if bytes.Equal(rest[:len(secret)], secret) {
+				out = append(out, buf[start:i]...)
+				out = append(out, redactedValue...)
+				i += len(secret)
+				start = i
+				continue scan
+			}
+		}
+
+		i++
+	}
+
+	out = append(out, buf[start:i]...)
+	r.pending = append(r.pending[:0], buf[i:]...)
+	r.scratch = out
+
+	_, err := r.out.Write(out)
+	return err
+}
+
+// secretValues returns the SecureString values in results, skipping any
+// whose env name appears in exclude.
+func secretValues(results []ParamResult, exclude []string) []string {
+	skip := map[string]bool{}
+	for _, name := range exclude {
+		skip[name] = true
+	}
+
+	secrets := []string{}
+	for _, param := range results {
+		if param.Type == ssm.ParameterTypeSecureString && !skip[param.EnvName] {
+			secrets = append(secrets, param.Value)
+		}
+	}
+
+	return secrets
+}
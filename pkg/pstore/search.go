@@ -0,0 +1,28 @@
+package pstore
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// SearchParameters returns the metadata of every parameter in the account
+// and region matching all of filters, sorted by name.
+func SearchParameters(sess *session.Session, filters ...*ssm.ParameterStringFilter) ([]*ssm.ParameterMetadata, error) {
+	api := ssm.New(sess)
+	params := []*ssm.ParameterMetadata{}
+
+	err := api.DescribeParametersPages(&ssm.DescribeParametersInput{
+		ParameterFilters: filters,
+	}, func(page *ssm.DescribeParametersOutput, lastPage bool) bool {
+		params = append(params, page.Parameters...)
+		return !lastPage
+	})
+
+	sort.Slice(params, func(i, j int) bool {
+		return *params[i].Name < *params[j].Name
+	})
+
+	return params, err
+}
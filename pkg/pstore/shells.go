@@ -0,0 +1,125 @@
+package pstore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	ShellSh   = "sh"
+	ShellBash = "bash"
+	ShellZsh  = "zsh"
+	ShellFish = "fish"
+	ShellTcsh = "tcsh"
+	ShellNu   = "nu"
+)
+
+// shellsByName maps the names shells are installed under to the dialect
+// their output should be written in.
+var shellsByName = map[string]string{
+	"sh":   ShellSh,
+	"dash": ShellSh,
+	"ash":  ShellSh,
+	"ksh":  ShellSh,
+	"bash": ShellBash,
+	"zsh":  ShellZsh,
+	"fish": ShellFish,
+	"tcsh": ShellTcsh,
+	"csh":  ShellTcsh,
+	"nu":   ShellNu,
+}
+
+// DetectShell is the dialect of the user's $SHELL, falling back to sh.
+func DetectShell() string {
+	if dialect, ok := shellsByName[filepath.Base(os.Getenv("SHELL"))]; ok {
+		return dialect
+	}
+	return ShellSh
+}
+
+// ValidShell returns an error unless dialect is one ShellExport can write.
+func ValidShell(dialect string) error {
+	switch dialect {
+	case ShellSh, ShellBash, ShellZsh, ShellFish, ShellTcsh, ShellNu:
+		return nil
+	default:
+		return fmt.Errorf("unknown shell '%s', expected one of sh, bash, zsh, fish, tcsh or nu", dialect)
+	}
+}
+
+// ShellExport is a statement that sets the environment variable key to
+// value in the given shell dialect. The value is quoted so that it's taken
+// literally, newlines included.
+func ShellExport(dialect, key, value string) (string, error) {
+	if err := ValidShell(dialect); err != nil {
+		return "", err
+	}
+
+	if !validEnvName.MatchString(key) {
+		return "", fmt.Errorf("%s isn't a valid environment variable name", key)
+	}
+
+	switch dialect {
+	case ShellSh, ShellBash, ShellZsh:
+		return fmt.Sprintf("export %s=%s", key, quotePosix(value)), nil
+	case ShellFish:
+		return fmt.Sprintf("set -gx %s %s", key, quoteFish(value)), nil
+	case ShellTcsh:
+		return fmt.Sprintf("setenv %s %s", key, quoteTcsh(value)), nil
+	default:
+		return fmt.Sprintf("$env.%s = %s", key, quoteNu(value)), nil
+	}
+}
+
+// quotePosix single-quotes value. Nothing is special inside single quotes,
+// so a quote is written by closing the string, escaping it and reopening.
+func quotePosix(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
+
+// quoteFish single-quotes value. fish treats backslash as an escape inside
+// single quotes, but only before another backslash or a quote.
+func quoteFish(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// quoteTcsh single-quotes value. Unlike POSIX shells, history expansion
+// still happens inside single quotes and newlines have to be escaped.
+func quoteTcsh(value string) string {
+	return "'" + strings.NewReplacer(`'`, `'\''`, "!", `\!`, "\n", "\\\n").Replace(value) + "'"
+}
+
+// quoteNu writes value as a raw string, with enough hashes that the value
+// can't contain its terminator.
+func quoteNu(value string) string {
+	hashes := "#"
+	for strings.Contains(value, "'"+hashes) {
+		hashes += "#"
+	}
+	return "r" + hashes + "'" + value + "'" + hashes
+}
+
+// ShellUnset is a statement that removes the environment variable key in
+// the given shell dialect.
+func ShellUnset(dialect, key string) (string, error) {
+	if err := ValidShell(dialect); err != nil {
+		return "", err
+	}
+
+	if !validEnvName.MatchString(key) {
+		return "", fmt.Errorf("%s isn't a valid environment variable name", key)
+	}
+
+	switch dialect {
+	case ShellSh, ShellBash, ShellZsh:
+		return "unset " + key, nil
+	case ShellFish:
+		return "set -e -g " + key, nil
+	case ShellTcsh:
+		return "unsetenv " + key, nil
+	default:
+		return "hide-env " + key, nil
+	}
+}
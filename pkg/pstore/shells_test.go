@@ -0,0 +1,119 @@
+package pstore
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// shellRunners source a file of statements in each dialect and print V.
+var shellRunners = map[string][]string{
+	ShellSh:   {"sh", "-c", `. "$0"; printenv V`},
+	ShellBash: {"bash", "-c", `source "$0"; printenv V`},
+	ShellZsh:  {"zsh", "-c", `source "$0"; printenv V`},
+	ShellFish: {"fish", "-c", `source $argv[1]; printenv V`},
+	ShellTcsh: {"tcsh", "-f", "-c", `source $argv[1]; printenv V`},
+}
+
+var shellAlphabet = []rune("ab Z09'\"\\$`!#*?~&|;<>(){}[]%^\n\t\r=-é日‘’")
+
+func randomShellValue(rnd *rand.Rand) string {
+	value := make([]rune, rnd.Intn(24))
+	for i := range value {
+		value[i] = shellAlphabet[rnd.Intn(len(shellAlphabet))]
+	}
+	return string(value)
+}
+
+func TestShellExportRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pstore-shells")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for dialect, runner := range shellRunners {
+		runner := runner
+		t.Run(dialect, func(t *testing.T) {
+			if _, err := exec.LookPath(runner[0]); err != nil {
+				t.Skipf("%s isn't installed", runner[0])
+			}
+
+			rnd := rand.New(rand.NewSource(1))
+			values := []string{"", "'", `\`, "it's", "a\nb\n", "!!", "$HOME", "`id`", "'#x"}
+			for i := 0; i < 200; i++ {
+				values = append(values, randomShellValue(rnd))
+			}
+
+			for idx, value := range values {
+				statement, err := ShellExport(dialect, "V", value)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				path := filepath.Join(dir, dialect+".env")
+				if err := ioutil.WriteFile(path, []byte(statement+"\n"), 0600); err != nil {
+					t.Fatal(err)
+				}
+
+				args := append(append([]string{}, runner[1:]...), path)
+				out, err := exec.Command(runner[0], args...).Output()
+				if err != nil {
+					t.Fatalf("value %d %q: %s: %v", idx, value, statement, err)
+				}
+
+				if got := strings.TrimSuffix(string(out), "\n"); got != value {
+					t.Errorf("value %d: got %q, want %q from %s", idx, got, value, statement)
+				}
+			}
+		})
+	}
+}
+
+func TestShellExportInvalidName(t *testing.T) {
+	if _, err := ShellExport(ShellBash, "NOT-VALID", "x"); err == nil {
+		t.Error("expected an error for an invalid env var name")
+	}
+	if _, err := ShellExport("cmd", "V", "x"); err == nil {
+		t.Error("expected an error for an unknown shell")
+	}
+}
+
+func TestQuoteNu(t *testing.T) {
+	tests := map[string]string{
+		"plain":  "r#'plain'#",
+		"it's":   "r#'it's'#",
+		"a'#b":   "r##'a'#b'##",
+		"'#'##x": "r###''#'##x'###",
+	}
+
+	for value, want := range tests {
+		if got := quoteNu(value); got != want {
+			t.Errorf("quoteNu(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestStatementFormatterSkipsInvalidNames(t *testing.T) {
+	params := []ParamResult{
+		{EnvName: "NOT-VALID", ParamName: "/app/not-valid", Value: "x"},
+		{EnvName: "VALID", ParamName: "/app/valid", Value: "y"},
+	}
+
+	for _, format := range []string{ShellBash, ShellFish, FormatPowershell, FormatCmd} {
+		formatter, _ := LookupFormatter(format)
+
+		out := &strings.Builder{}
+		if err := formatter(out, params); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+
+		if strings.Contains(out.String(), "NOT-VALID") || !strings.Contains(out.String(), "VALID") {
+			t.Errorf("%s: got %q, want only VALID set", format, out.String())
+		}
+	}
+}
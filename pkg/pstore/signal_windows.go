@@ -0,0 +1,24 @@
+package pstore
+
+import (
+	"os"
+	"syscall"
+)
+
+var terminateSignal = os.Kill
+
+var forwardedSignals = []os.Signal{
+	os.Interrupt,
+}
+
+// ExitSignals are the signals that end pstore, which commands holding
+// secrets on disk clean up after. Closing the console arrives as SIGTERM.
+var ExitSignals = []os.Signal{
+	os.Interrupt,
+	syscall.SIGTERM,
+}
+
+var signalsByName = map[string]os.Signal{
+	"INT":  os.Interrupt,
+	"KILL": os.Kill,
+}
@@ -0,0 +1,255 @@
+package pstore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// how long a child gets to exit after being asked to before it is killed
+const restartGracePeriod = 10 * time.Second
+
+// Supervisor runs a command as a child process rather than replacing pstore
+// with it, so that pstore can keep watching the parameters it resolved.
+type Supervisor struct {
+	Options  Options
+	Args     []string
+	Interval time.Duration // how often to poll parameters, zero disables watching
+	Signal   os.Signal     // sent to the child on change, nil restarts it instead
+	Debounce time.Duration // quiet period after a change before acting on it
+
+	Redact        bool     // mask SecureString values in the child's output
+	RedactExclude []string // env names whose values are never masked
+
+	stdout io.Writer
+	stderr io.Writer
+}
+
+type child struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+	err  error
+}
+
+func ParseSignal(name string) (os.Signal, error) {
+	name = strings.TrimPrefix(strings.ToUpper(name), "SIG")
+	if sig, ok := signalsByName[name]; ok {
+		return sig, nil
+	}
+	return nil, fmt.Errorf("unsupported signal '%s'", name)
+}
+
+// Run starts the child and never returns: pstore exits with the child's
+// status once it exits of its own accord.
+func (s *Supervisor) Run() {
+	if len(s.Args) == 0 {
+		Abort(UsageError, "no command specified")
+	}
+
+	opts := s.Options
+	req := GetParamRequestFromEnv(opts.SimplePrefix, opts.TagPrefix, opts.PathPrefix)
+
+	var sess *session.Session
+	results := []ParamResult{}
+
+	if !req.Empty() {
+		sess = NewSession()
+		results = fetchParams(sess, req)
+		if !reportResults(results, opts) {
+			Abort(PstoreError, "Failed to decrypt some secret values")
+		}
+	} else {
+		writeReport(results, opts)
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, forwardedSignals...)
+
+	var changes <-chan []ParamResult
+	if s.Interval > 0 && sess != nil {
+		changes = s.watch(sess, req, results)
+	}
+
+	s.stdout, s.stderr = os.Stdout, os.Stderr
+	var redactors []*Redactor
+	if s.Redact {
+		redactors = []*Redactor{NewRedactor(os.Stdout), NewRedactor(os.Stderr)}
+		s.stdout, s.stderr = redactors[0], redactors[1]
+	}
+
+	setSecrets := func(results []ParamResult) {
+		secrets := secretValues(results, s.RedactExclude)
+		for _, r := range redactors {
+			r.SetSecrets(secrets)
+		}
+	}
+
+	flush := func() {
+		for _, r := range redactors {
+			r.Flush()
+		}
+	}
+
+	setSecrets(results)
+	c := s.start(results)
+	latest := results
+	var debounce <-chan time.Time
+
+	for {
+		select {
+		case sig := <-sigs:
+			c.cmd.Process.Signal(sig)
+		case <-c.done:
+			flush()
+			os.Exit(exitStatus(c.err))
+		case latest = <-changes:
+			debounce = time.After(s.Debounce)
+		case <-debounce:
+			debounce = nil
+			if s.Signal != nil {
+				s.logWatch(WatchEvent{Event: WatchSignal, Message: fmt.Sprintf("Sending %s to child process", s.Signal)})
+				c.cmd.Process.Signal(s.Signal)
+			} else {
+				s.logWatch(WatchEvent{Event: WatchRestart, Message: "Restarting child process"})
+				s.stop(c)
+				flush()
+				setSecrets(latest)
+				c = s.start(latest)
+			}
+		}
+	}
+}
+
+func (s *Supervisor) start(results []ParamResult) *child {
+	cmd := exec.Command(s.Args[0], s.Args[1:]...)
+	cmd.Env = mergeEnv(os.Environ(), results)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = s.stdout
+	cmd.Stderr = s.stderr
+
+	if err := cmd.Start(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			Abort(CommandNotFoundError, fmt.Sprintf("cannot find '%s'", s.Args[0]))
+		}
+		Abort(ExecError, err)
+	}
+
+	c := &child{cmd: cmd, done: make(chan struct{})}
+	go func() {
+		c.err = cmd.Wait()
+		close(c.done)
+	}()
+
+	return c
+}
+
+func (s *Supervisor) stop(c *child) {
+	c.cmd.Process.Signal(terminateSignal)
+
+	select {
+	case <-c.done:
+	case <-time.After(restartGracePeriod):
+		c.cmd.Process.Kill()
+		<-c.done
+	}
+}
+
+// watch polls the versions of the parameters in req, without decrypting
+// them, and emits the complete set of results every time any of them
+// changes. Values are only fetched once a version has changed.
+func (s *Supervisor) watch(sess *session.Session, req ParamsRequest, initial []ParamResult) <-chan []ParamResult {
+	changes := make(chan []ParamResult)
+
+	go func() {
+		versions := versionsByEnvName(initial)
+
+		for range time.Tick(s.Interval) {
+			planned, err := Plan(sess, req)
+			if err != nil {
+				s.logWatch(WatchEvent{Event: WatchPollFailed, Level: "error", Message: fmt.Sprintf("Failed to poll parameters, keeping current values: %s", err)})
+				continue
+			}
+
+			changed := false
+			polled := map[string]bool{}
+			for _, param := range planned {
+				if param.EnvName == "" {
+					continue
+				}
+				polled[param.EnvName] = true
+
+				if version, ok := versions[param.EnvName]; !ok || version != param.Version {
+					s.logWatch(WatchEvent{
+						Event:     WatchChanged,
+						Message:   fmt.Sprintf("%s changed to version %d", param.ParamName, param.Version),
+						ParamName: param.ParamName,
+						Version:   param.Version,
+					})
+					changed = true
+				}
+			}
+
+			// a parameter that's gone away changes the env too
+			if !changed && len(polled) == len(versions) {
+				continue
+			}
+
+			results := fetchParams(sess, req)
+			if !succeeded(results) {
+				logResults(results, s.Options.LogFormat, false)
+				s.logWatch(WatchEvent{Event: WatchPollFailed, Level: "error", Message: "Failed to poll parameters, keeping current values"})
+				continue
+			}
+
+			versions = versionsByEnvName(results)
+			changes <- results
+		}
+	}()
+
+	return changes
+}
+
+func versionsByEnvName(results []ParamResult) map[string]int64 {
+	versions := map[string]int64{}
+	for _, param := range results {
+		versions[param.EnvName] = param.Version
+	}
+	return versions
+}
+
+func mergeEnv(environ []string, results []ParamResult) []string {
+	env := append([]string{}, environ...)
+	for _, param := range results {
+		env = append(env, param.EnvName+"="+param.Value)
+	}
+	return env
+}
+
+func exitStatus(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		// a child killed by a signal has no exit code of its own, so report
+		// it the way shells do
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			return 128 + int(status.Signal())
+		}
+		return exitErr.ExitCode()
+	}
+
+	return ExecError
+}
+
+func (s *Supervisor) logWatch(event WatchEvent) {
+	logWatchEvent(s.Options.LogFormat, event)
+}
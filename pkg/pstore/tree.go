@@ -0,0 +1,126 @@
+package pstore
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// GetParameterTree returns the parameters under path, sorted by name. Only
+// parameters matching all of filters are returned.
+func GetParameterTree(sess *session.Session, path string, recursive, decrypt bool, filters ...*ssm.ParameterStringFilter) ([]*ssm.Parameter, error) {
+	api := ssm.New(sess)
+	params := []*ssm.Parameter{}
+
+	err := api.GetParametersByPathPages(&ssm.GetParametersByPathInput{
+		Path:             aws.String(path),
+		Recursive:        aws.Bool(recursive),
+		WithDecryption:   aws.Bool(decrypt),
+		ParameterFilters: filters,
+	}, func(page *ssm.GetParametersByPathOutput, lastPage bool) bool {
+		params = append(params, page.Parameters...)
+		return !lastPage
+	})
+
+	sort.Slice(params, func(i, j int) bool {
+		return *params[i].Name < *params[j].Name
+	})
+
+	return params, err
+}
+
+// DescribeParameterTree returns the metadata of the parameters under path,
+// keyed by name. Only parameters matching all of filters are returned.
+func DescribeParameterTree(sess *session.Session, path string, recursive bool, filters ...*ssm.ParameterStringFilter) (map[string]*ssm.ParameterMetadata, error) {
+	api := ssm.New(sess)
+	metadata := map[string]*ssm.ParameterMetadata{}
+
+	option := "OneLevel"
+	if recursive {
+		option = "Recursive"
+	}
+
+	err := api.DescribeParametersPages(&ssm.DescribeParametersInput{
+		ParameterFilters: append([]*ssm.ParameterStringFilter{
+			{Key: aws.String("Path"), Option: aws.String(option), Values: aws.StringSlice([]string{path})},
+		}, filters...),
+	}, func(page *ssm.DescribeParametersOutput, lastPage bool) bool {
+		for _, param := range page.Parameters {
+			metadata[*param.Name] = param
+		}
+		return !lastPage
+	})
+
+	return metadata, err
+}
+
+// RelativeName is name with path and the slash that follows it removed.
+func RelativeName(path, name string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(name, path), "/")
+}
+
+// JoinName is the inverse of RelativeName.
+func JoinName(path, relative string) string {
+	return strings.TrimSuffix(path, "/") + "/" + relative
+}
+
+// DescribeParameterNames returns the metadata of the named parameters,
+// keyed by name. Names that don't exist are left out.
+func DescribeParameterNames(sess *session.Session, names []string) (map[string]*ssm.ParameterMetadata, error) {
+	api := ssm.New(sess)
+	metadata := map[string]*ssm.ParameterMetadata{}
+
+	for start := 0; start < len(names); start += describeFilterValuesLimit {
+		end := start + describeFilterValuesLimit
+		if end > len(names) {
+			end = len(names)
+		}
+
+		err := api.DescribeParametersPages(&ssm.DescribeParametersInput{
+			ParameterFilters: []*ssm.ParameterStringFilter{
+				{Key: aws.String("Name"), Option: aws.String("Equals"), Values: aws.StringSlice(names[start:end])},
+			},
+		}, func(page *ssm.DescribeParametersOutput, lastPage bool) bool {
+			for _, param := range page.Parameters {
+				metadata[*param.Name] = param
+			}
+			return !lastPage
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return metadata, nil
+}
+
+// GetParametersByName returns the named parameters, keyed by name. Names
+// that don't exist are left out.
+func GetParametersByName(sess *session.Session, names []string, decrypt bool) (map[string]*ssm.Parameter, error) {
+	api := ssm.New(sess)
+	params := map[string]*ssm.Parameter{}
+
+	for start := 0; start < len(names); start += getParametersBatchSize {
+		end := start + getParametersBatchSize
+		if end > len(names) {
+			end = len(names)
+		}
+
+		resp, err := api.GetParameters(&ssm.GetParametersInput{
+			Names:          aws.StringSlice(names[start:end]),
+			WithDecryption: aws.Bool(decrypt),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, param := range resp.Parameters {
+			params[*param.Name] = param
+		}
+	}
+
+	return params, nil
+}
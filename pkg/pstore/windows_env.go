@@ -0,0 +1,87 @@
+package pstore
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	FormatPowershell = "powershell"
+	FormatPs1        = "ps1"
+	FormatCmd        = "cmd"
+)
+
+// utf8BOM marks .ps1 files as UTF-8, without which Windows PowerShell reads
+// them in the ANSI code page.
+const utf8BOM = "\ufeff"
+
+// PowershellExport is a statement that sets the environment variable key
+// to value. The value is written as a single-quoted literal, which
+// PowerShell never expands, with every kind of single quote it recognises
+// doubled.
+func PowershellExport(key, value string) (string, error) {
+	if !validEnvName.MatchString(key) {
+		return "", fmt.Errorf("%s isn't a valid environment variable name", key)
+	}
+
+	escaped := strings.NewReplacer("'", "''", "‘", "‘‘", "’", "’’", "‚", "‚‚", "‛", "‛‛").Replace(value)
+	return fmt.Sprintf("${Env:%s} = '%s'", key, escaped), nil
+}
+
+// Ps1Header starts a .ps1 file of PowershellExport statements.
+func Ps1Header() string {
+	return utf8BOM + "# Environment from pstore, dot-source it with: . .\\env.ps1"
+}
+
+// CmdExport is a cmd.exe batch file statement that sets the environment
+// variable key to value. Percent signs are doubled, and any of cmd's
+// special characters that a quote in the value leaves unquoted are escaped
+// with a caret. ! is left alone, as batch files don't expand it unless
+// delayed expansion has been turned on. cmd has no way to set a value with
+// a newline in it.
+func CmdExport(key, value string) (string, error) {
+	if !validEnvName.MatchString(key) {
+		return "", fmt.Errorf("%s isn't a valid environment variable name", key)
+	}
+
+	if strings.ContainsAny(value, "\r\n") {
+		return "", fmt.Errorf("%s has a newline, which cmd can't set", key)
+	}
+
+	escaped := strings.Builder{}
+	quoted := true
+	for _, r := range value {
+		switch {
+		case r == '%':
+			escaped.WriteString("%%")
+			continue
+		case r == '"':
+			quoted = !quoted
+		case !quoted && strings.ContainsRune("^&|<>()", r):
+			escaped.WriteRune('^')
+		}
+		escaped.WriteRune(r)
+	}
+
+	return fmt.Sprintf(`set "%s=%s"`, key, escaped.String()), nil
+}
+
+// PowershellUnset is a statement that removes the environment variable key,
+// whether or not it's set.
+func PowershellUnset(key string) (string, error) {
+	if !validEnvName.MatchString(key) {
+		return "", fmt.Errorf("%s isn't a valid environment variable name", key)
+	}
+
+	return fmt.Sprintf("Remove-Item -LiteralPath Env:%s -ErrorAction SilentlyContinue", key), nil
+}
+
+// CmdUnset is a cmd.exe batch file statement that removes the environment
+// variable key.
+func CmdUnset(key string) (string, error) {
+	if !validEnvName.MatchString(key) {
+		return "", fmt.Errorf("%s isn't a valid environment variable name", key)
+	}
+
+	return fmt.Sprintf(`set "%s="`, key), nil
+}
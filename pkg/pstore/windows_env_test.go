@@ -0,0 +1,69 @@
+package pstore
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/*.golden files")
+
+var windowsValues = []ParamResult{
+	{EnvName: "EMPTY", Value: ""},
+	{EnvName: "SINGLE_QUOTE", Value: "it's"},
+	{EnvName: "SMART_QUOTES", Value: "‘a’ ‚b‛"},
+	{EnvName: "DOUBLE_QUOTE", Value: `say "hi" & "bye" | more`},
+	{EnvName: "PERCENT", Value: "100% of %PATH%"},
+	{EnvName: "CARET", Value: "a^b"},
+	{EnvName: "BANG", Value: "wow!"},
+	{EnvName: "AMPERSAND", Value: "a&b"},
+	{EnvName: "PIPE", Value: "a|b"},
+	{EnvName: "ODD_QUOTE", Value: `x" & y | z ^ (w)`},
+	{EnvName: "SPECIALS", Value: "<in> (x) $HOME `whoami`"},
+	{EnvName: "UNICODE", Value: "héllo 日本"},
+}
+
+func testGolden(t *testing.T, format, golden string, params []ParamResult) {
+	formatter, err := LookupFormatter(format)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	if err := formatter(out, params); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join("testdata", golden)
+	if *update {
+		if err := ioutil.WriteFile(path, out.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(out.Bytes(), want) {
+		t.Errorf("%s output doesn't match %s:\n%s", format, path, out.String())
+	}
+}
+
+func TestPs1Golden(t *testing.T) {
+	params := append(windowsValues, ParamResult{EnvName: "MULTILINE", Value: "line 1\nline 2"})
+	testGolden(t, FormatPs1, "env.ps1.golden", params)
+}
+
+func TestCmdGolden(t *testing.T) {
+	testGolden(t, FormatCmd, "env.cmd.golden", windowsValues)
+}
+
+func TestCmdRejectsNewlines(t *testing.T) {
+	if _, err := CmdExport("V", "line 1\nline 2"); err == nil {
+		t.Error("expected an error for a value with a newline")
+	}
+}